@@ -2,14 +2,17 @@ package chaos
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"time"
+	"sort"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -17,134 +20,390 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-// Client represents a Chaos Mesh client
+// Client represents a Chaos Mesh client. It is safe for concurrent use.
 type Client struct {
 	dynamicClient dynamic.Interface
-	logger        log.Entry
+	// mapper resolves kinds to resources, falling back to the known chaos kinds when nil
+	mapper meta.RESTMapper
+	// discovery lists the installed chaos kinds, nil when only the known kinds are used
+	discovery discovery.DiscoveryInterface
+	logger    log.Entry
+	// informers share the watch streams of experiments waited for by WatchExperiment
+	informers informerHub
+}
+
+const (
+	// DefaultQPS is the default client-side rate limit for API requests
+	DefaultQPS = 20
+	// DefaultBurst is the default burst allowed above DefaultQPS
+	DefaultBurst = 40
+	// DefaultUserAgent identifies the plugin in API server logs and audit events
+	DefaultUserAgent = "argo-rollouts-chaos-mesh-plugin"
+)
+
+// ClientOptions tunes the connection to the Kubernetes API server
+type ClientOptions struct {
+	QPS       float32
+	Burst     int
+	UserAgent string
 }
 
 // NewClient creates a new Chaos Mesh client
-func NewClient(logger log.Entry) (*Client, error) {
+func NewClient(logger log.Entry, opts ClientOptions) (*Client, error) {
 	config, err := rest.InClusterConfig()
 	if err != nil {
 		// Fallback to kubeconfig if not running in cluster
 		config, err = clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create kubernetes config: %w", err)
+			return nil, fmt.Errorf("no Kubernetes cluster configuration available (not running in a cluster and no usable kubeconfig at %s): %w", clientcmd.RecommendedHomeFile, err)
 		}
 	}
 
+	config.QPS = DefaultQPS
+	if opts.QPS > 0 {
+		config.QPS = opts.QPS
+	}
+	config.Burst = DefaultBurst
+	if opts.Burst > 0 {
+		config.Burst = opts.Burst
+	}
+	config.UserAgent = DefaultUserAgent
+	if opts.UserAgent != "" {
+		config.UserAgent = opts.UserAgent
+	}
+
 	dynamicClient, err := dynamic.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
 	}
 
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+
+	return NewClientWithDiscovery(dynamicClient, discoveryClient, logger), nil
+}
+
+// NewClientWithDynamic creates a Chaos Mesh client on top of an existing dynamic client. Kinds
+// are resolved from the chaos kinds of chaos-mesh.org/v1alpha1 known to the plugin.
+func NewClientWithDynamic(dynamicClient dynamic.Interface, logger log.Entry) *Client {
 	return &Client{
 		dynamicClient: dynamicClient,
 		logger:        logger,
-	}, nil
+	}
 }
 
-// CreateExperiment creates a Chaos Mesh experiment from YAML
-func (c *Client) CreateExperiment(ctx context.Context, experimentYAML string, targetSelector map[string]string) (*unstructured.Unstructured, error) {
-	// Parse the YAML
+// NewClientWithDiscovery creates a Chaos Mesh client that resolves kinds from the discovery
+// data of the API server, cached in memory
+func NewClientWithDiscovery(dynamicClient dynamic.Interface, discoveryClient discovery.DiscoveryInterface, logger log.Entry) *Client {
+	mapper, cached := newDiscoveryMapper(discoveryClient)
+	return &Client{
+		dynamicClient: dynamicClient,
+		mapper:        mapper,
+		discovery:     cached,
+		logger:        logger,
+	}
+}
+
+// ParseExperiment parses a Chaos Mesh experiment definition from YAML
+func ParseExperiment(experimentYAML string) (*unstructured.Unstructured, error) {
 	var obj unstructured.Unstructured
 	if err := yaml.Unmarshal([]byte(experimentYAML), &obj); err != nil {
 		return nil, fmt.Errorf("failed to parse experiment YAML: %w", err)
 	}
+	return &obj, nil
+}
+
+// CreateExperiment creates a Chaos Mesh experiment from YAML, stamped with the tracking labels
+// and annotations so it can be found again by a later plugin process. Experiments in the
+// namespace of the AnalysisRun are owned by it and removed together with it.
+//
+// The experiment name is suffixed with the measurement identity so repeated measurements of a
+// metric do not collide; metadata.generateName is honoured when no name is given. If an
+// experiment for the same measurement already exists, e.g. because Run is retried, it is
+// adopted instead of created again.
+//
+// The experiment is created in namespace unless the YAML sets its own. The target selector is
+// injected into every pod selector of the experiment, and the template labels it changed or
+// dropped are returned so they can be reported.
+func (c *Client) CreateExperiment(ctx context.Context, experimentYAML string, namespace string, target TargetSelector, tracking Tracking) (*unstructured.Unstructured, []SelectorOverride, error) {
+	// Parse the YAML
+	obj, err := ParseExperiment(experimentYAML)
+	if err != nil {
+		return nil, nil, err
+	}
+	if obj.GetName() == "" && obj.GetGenerateName() == "" {
+		return nil, nil, fmt.Errorf("experiment requires metadata.name or metadata.generateName")
+	}
 
 	// Inject the target selector
-	if err := c.injectSelector(&obj, targetSelector); err != nil {
-		return nil, fmt.Errorf("failed to inject selector: %w", err)
+	overrides, err := c.injectSelector(obj, target)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to inject selector: %w", err)
+	}
+	for _, override := range overrides {
+		c.logger.Warnf("Target selector overrides template label of %s: %s", obj.GetName(), override)
 	}
 
 	// Get the GVR for the resource
-	gvr, err := c.getGVR(obj.GetKind())
+	gvr, err := c.getGVR(obj.GetAPIVersion(), obj.GetKind())
 	if err != nil {
-		return nil, fmt.Errorf("failed to get GVR for kind %s: %w", obj.GetKind(), err)
+		return nil, nil, err
 	}
 
 	// Create the resource
-	namespace := obj.GetNamespace()
+	if obj.GetNamespace() != "" {
+		namespace = obj.GetNamespace()
+	}
 	if namespace == "" {
 		namespace = "default"
 	}
 
+	adoptable := tracking.AnalysisRunUID != ""
+	if adoptable {
+		existing, err := c.findNewest(ctx, gvr, namespace, tracking.IdentitySelector())
+		if err == nil {
+			c.logger.Infof("Adopting existing Chaos Mesh experiment: %s/%s", existing.GetNamespace(), existing.GetName())
+			return existing, overrides, nil
+		}
+		if !errors.IsNotFound(err) {
+			return nil, nil, fmt.Errorf("failed to look up existing experiment: %w", err)
+		}
+	}
+
+	if obj.GetName() != "" {
+		obj.SetName(suffixedName(obj.GetName(), tracking.Identity()))
+	}
+	tracking.apply(obj)
+	tracking.setOwner(obj, namespace)
+
 	c.logger.Infof("Creating Chaos Mesh experiment: %s/%s", namespace, obj.GetName())
 	
-	result, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Create(ctx, &obj, metav1.CreateOptions{})
+	result, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Create(ctx, obj, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) && adoptable {
+		// A concurrent Run for the same measurement may have won the race
+		if existing, findErr := c.findNewest(ctx, gvr, namespace, tracking.IdentitySelector()); findErr == nil {
+			c.logger.Infof("Adopting existing Chaos Mesh experiment: %s/%s", existing.GetNamespace(), existing.GetName())
+			return existing, overrides, nil
+		}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create experiment: %w", err)
+		return nil, nil, fmt.Errorf("failed to create experiment: %w", err)
 	}
 
-	return result, nil
+	return result, overrides, nil
 }
 
-// WatchExperiment watches a Chaos Mesh experiment until completion or timeout
-func (c *Client) WatchExperiment(ctx context.Context, namespace, name, kind string, timeout time.Duration) (bool, error) {
-	gvr, err := c.getGVR(kind)
+// GetExperiment fetches the current state of a Chaos Mesh experiment. If the reference
+// carries a UID and the object by that name has a different one, the experiment is
+// reported as not found since the original object no longer exists.
+func (c *Client) GetExperiment(ctx context.Context, ref ExperimentRef) (*unstructured.Unstructured, error) {
+	gvr, err := c.getGVR(ref.APIVersion, ref.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := c.dynamicClient.Resource(gvr).Namespace(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
 	if err != nil {
-		return false, fmt.Errorf("failed to get GVR for kind %s: %w", kind, err)
+		return nil, fmt.Errorf("failed to get experiment: %w", err)
+	}
+
+	if ref.UID != "" && obj.GetUID() != ref.UID {
+		c.logger.Warnf("Experiment %s/%s was replaced (expected UID %s, found %s)", ref.Namespace, ref.Name, ref.UID, obj.GetUID())
+		return nil, fmt.Errorf("failed to get experiment: %w", errors.NewNotFound(gvr.GroupResource(), ref.Name))
 	}
 
-	c.logger.Infof("Watching Chaos Mesh experiment: %s/%s", namespace, name)
+	return obj, nil
+}
 
-	// Create a context with timeout
-	watchCtx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+// ExperimentStatus reports whether an experiment has finished and, if so, whether it succeeded.
+// An experiment still waiting for its targets has failed when the chaos-controller-manager
+// recorded that it could not select them, see SelectionFailed.
+func (c *Client) ExperimentStatus(ctx context.Context, obj *unstructured.Unstructured) (success bool, finished bool, err error) {
+	switch obj.GetKind() {
+	case KindWorkflow:
+		return workflowStatus(obj)
+	case KindSchedule:
+		// Schedules keep spawning runs until they are stopped, see ListScheduleRuns
+		return false, false, nil
+	}
+	success, finished, err = c.checkExperimentStatus(obj)
+	if err != nil || finished || !selectionPending(obj) {
+		return success, finished, err
+	}
 
-	// Start watching
-	watcher, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Watch(watchCtx, metav1.ListOptions{
-		FieldSelector: fmt.Sprintf("metadata.name=%s", name),
+	failed, err := c.SelectionFailed(ctx, RefFor(obj))
+	if err != nil {
+		// The experiment is judged again on its next change
+		c.logger.Warnf("Failed to check whether experiment %s/%s selected its targets: %v", obj.GetNamespace(), obj.GetName(), err)
+		return false, false, nil
+	}
+	if failed {
+		c.logger.Debugf("Experiment %s failed to select its targets", obj.GetName())
+	}
+	return false, failed, nil
+}
+
+// FindExperiment returns the most recently created experiment of the given kind carrying
+// the tracking labels. An empty namespace searches all namespaces.
+func (c *Client) FindExperiment(ctx context.Context, apiVersion, kind, namespace string, tracking Tracking) (*unstructured.Unstructured, error) {
+	gvr, err := c.getGVR(apiVersion, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	experiment, err := c.findNewest(ctx, gvr, namespace, tracking.Selector())
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.Infof("Found chaos experiment %s/%s for AnalysisRun %s/%s", experiment.GetNamespace(), experiment.GetName(), tracking.AnalysisRunNamespace, tracking.AnalysisRunName)
+	return experiment, nil
+}
+
+// findNewest returns the most recently created object of gvr matching the label selector
+func (c *Client) findNewest(ctx context.Context, gvr schema.GroupVersionResource, namespace, labelSelector string) (*unstructured.Unstructured, error) {
+	list, err := c.dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
 	})
 	if err != nil {
-		return false, fmt.Errorf("failed to start watching experiment: %w", err)
+		return nil, fmt.Errorf("failed to list experiments: %w", err)
 	}
-	defer watcher.Stop()
 
-	for {
-		select {
-		case event, ok := <-watcher.ResultChan():
-			if !ok {
-				return false, fmt.Errorf("watch channel closed unexpectedly")
-			}
+	var newest *unstructured.Unstructured
+	for i := range list.Items {
+		item := &list.Items[i]
+		if newest == nil || item.GetCreationTimestamp().Time.After(newest.GetCreationTimestamp().Time) {
+			newest = item
+		}
+	}
+	if newest == nil {
+		return nil, errors.NewNotFound(gvr.GroupResource(), labelSelector)
+	}
+	return newest, nil
+}
 
-			if event.Type == watch.Error {
-				return false, fmt.Errorf("watch error: %v", event.Object)
-			}
+// PauseExperiment pauses a Chaos Mesh experiment through the pause annotation. Chaos Mesh
+// recovers the injected fault but keeps the object and its records for investigation.
+// Workflows cannot be paused and are aborted instead.
+func (c *Client) PauseExperiment(ctx context.Context, ref ExperimentRef) error {
+	gvr, err := c.getGVR(ref.APIVersion, ref.Kind)
+	if err != nil {
+		return err
+	}
 
-			obj, ok := event.Object.(*unstructured.Unstructured)
-			if !ok {
-				continue
-			}
+	c.logger.Infof("Pausing Chaos Mesh experiment: %s/%s", ref.Namespace, ref.Name)
 
-			success, finished, err := c.checkExperimentStatus(obj)
-			if err != nil {
-				c.logger.Warnf("Error checking experiment status: %v", err)
-				continue
-			}
+	annotation := AnnotationPause
+	if ref.Kind == KindWorkflow {
+		annotation = AnnotationAbort
+	}
+	metadata := map[string]interface{}{
+		"annotations": map[string]interface{}{
+			annotation: "true",
+		},
+	}
+	// A UID in the patch makes it fail if the object was replaced
+	if ref.UID != "" {
+		metadata["uid"] = string(ref.UID)
+	}
+	patch, err := json.Marshal(map[string]interface{}{"metadata": metadata})
+	if err != nil {
+		return fmt.Errorf("failed to build pause patch: %w", err)
+	}
 
-			if finished {
-				c.logger.Infof("Experiment %s/%s finished with success=%t", namespace, name, success)
-				return success, nil
-			}
+	_, err = c.dynamicClient.Resource(gvr).Namespace(ref.Namespace).Patch(ctx, ref.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to pause experiment: %w", err)
+	}
+
+	return nil
+}
+
+// ListExperiments lists experiments of every installed chaos kind matching the label selector
+// across all namespaces
+func (c *Client) ListExperiments(ctx context.Context, labelSelector string) ([]unstructured.Unstructured, error) {
+	kinds, err := c.chaosKinds()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed chaos kinds: %w", err)
+	}
+
+	var experiments []unstructured.Unstructured
+	for _, kind := range kinds {
+		gvr, err := c.getGVR("", kind)
+		if err != nil {
+			return nil, err
+		}
+
+		list, err := c.dynamicClient.Resource(gvr).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+			LabelSelector: labelSelector,
+		})
+		if errors.IsNotFound(err) || errors.IsForbidden(err) {
+			c.logger.Debugf("Skipping chaos kind %s: %v", kind, err)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s experiments: %w", kind, err)
+		}
+
+		for _, item := range list.Items {
+			// List items carry no type information with some servers
+			item.SetKind(kind)
+			experiments = append(experiments, item)
+		}
+	}
+	return experiments, nil
+}
+
+// DeleteOldExperiments deletes all but the keep most recently created experiments
+// matching the label selector and returns how many were deleted
+func (c *Client) DeleteOldExperiments(ctx context.Context, labelSelector string, keep int) (int, error) {
+	experiments, err := c.ListExperiments(ctx, labelSelector)
+	if err != nil {
+		return 0, err
+	}
+	if keep < 0 {
+		keep = 0
+	}
+	if len(experiments) <= keep {
+		return 0, nil
+	}
+
+	sort.Slice(experiments, func(i, j int) bool {
+		return experiments[i].GetCreationTimestamp().Time.After(experiments[j].GetCreationTimestamp().Time)
+	})
 
-		case <-watchCtx.Done():
-			return false, fmt.Errorf("timeout waiting for experiment to complete")
+	deleted := 0
+	for i := keep; i < len(experiments); i++ {
+		if err := c.DeleteExperiment(ctx, RefFor(&experiments[i])); err != nil {
+			return deleted, err
 		}
+		deleted++
 	}
+	return deleted, nil
 }
 
-// DeleteExperiment deletes a Chaos Mesh experiment
-func (c *Client) DeleteExperiment(ctx context.Context, namespace, name, kind string) error {
-	gvr, err := c.getGVR(kind)
+// DeleteExperiment deletes a Chaos Mesh experiment. When the reference carries a UID
+// the delete is skipped if the object by that name has since been replaced.
+func (c *Client) DeleteExperiment(ctx context.Context, ref ExperimentRef) error {
+	gvr, err := c.getGVR(ref.APIVersion, ref.Kind)
 	if err != nil {
-		return fmt.Errorf("failed to get GVR for kind %s: %w", kind, err)
+		return err
 	}
 
-	c.logger.Infof("Deleting Chaos Mesh experiment: %s/%s", namespace, name)
+	c.logger.Infof("Deleting Chaos Mesh experiment: %s/%s", ref.Namespace, ref.Name)
+
+	opts := metav1.DeleteOptions{}
+	if ref.UID != "" {
+		opts.Preconditions = metav1.NewUIDPreconditions(string(ref.UID))
+	}
 
-	err = c.dynamicClient.Resource(gvr).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	err = c.dynamicClient.Resource(gvr).Namespace(ref.Namespace).Delete(ctx, ref.Name, opts)
+	if errors.IsConflict(err) {
+		c.logger.Warnf("Experiment %s/%s was replaced, not deleting it", ref.Namespace, ref.Name)
+		return nil
+	}
 	if err != nil && !errors.IsNotFound(err) {
 		return fmt.Errorf("failed to delete experiment: %w", err)
 	}
@@ -152,44 +411,50 @@ func (c *Client) DeleteExperiment(ctx context.Context, namespace, name, kind str
 	return nil
 }
 
-// injectSelector injects the target selector into the experiment spec
-func (c *Client) injectSelector(obj *unstructured.Unstructured, targetSelector map[string]string) error {
-	spec, found, err := unstructured.NestedMap(obj.Object, "spec")
-	if err != nil {
-		return fmt.Errorf("failed to get spec: %w", err)
+// injectSelector injects the target selector into the experiment spec. Kinds that do not
+// target pods, such as the cloud kinds, are left untouched.
+func (c *Client) injectSelector(obj *unstructured.Unstructured, target TargetSelector) ([]SelectorOverride, error) {
+	injection := &selectorInjection{target: target}
+	if err := c.inject(obj, injection); err != nil {
+		return nil, err
 	}
-	if !found {
-		return fmt.Errorf("spec not found in experiment")
+	return injection.overrides, nil
+}
+
+// inject runs the selector injection over the experiment, workflow or schedule
+func (c *Client) inject(obj *unstructured.Unstructured, injection *selectorInjection) error {
+	switch obj.GetKind() {
+	case KindWorkflow:
+		return c.injectWorkflowSelector(obj, injection)
+	case KindSchedule:
+		return c.injectScheduleSelector(obj, injection)
+	}
+	if !traitsOf(obj.GetKind()).selectsPods {
+		c.logger.Infof("Chaos kind %s does not select pods, not injecting the target selector", obj.GetKind())
+		return nil
 	}
 
-	// Get existing selector or create new one
-	selector, found, err := unstructured.NestedMap(spec, "selector")
+	spec, found, err := unstructured.NestedMap(obj.Object, "spec")
 	if err != nil {
-		return fmt.Errorf("failed to get selector: %w", err)
+		return fmt.Errorf("failed to get spec: %w", err)
 	}
 	if !found {
-		selector = make(map[string]interface{})
+		return fmt.Errorf("spec not found in experiment")
 	}
 
-	// Inject labelSelectors
-	if len(targetSelector) > 0 {
-		labelSelectors := make(map[string]interface{})
-		for k, v := range targetSelector {
-			labelSelectors[k] = v
-		}
-		selector["labelSelectors"] = labelSelectors
+	if err := injection.injectChaos(spec, obj.GetKind(), "spec"); err != nil {
+		return err
 	}
 
-	// Set the updated selector back
-	spec["selector"] = selector
+	// Set the updated spec back
 	if err := unstructured.SetNestedMap(obj.Object, spec, "spec"); err != nil {
 		return fmt.Errorf("failed to set updated spec: %w", err)
 	}
-
 	return nil
 }
 
-// checkExperimentStatus checks if the experiment is finished and successful
+// checkExperimentStatus checks if the experiment is finished and successful. The phase of
+// Chaos Mesh 1.x is used when present, see evaluateRecords for Chaos Mesh 2.x.
 func (c *Client) checkExperimentStatus(obj *unstructured.Unstructured) (success bool, finished bool, err error) {
 	status, found, err := unstructured.NestedMap(obj.Object, "status")
 	if err != nil {
@@ -206,7 +471,7 @@ func (c *Client) checkExperimentStatus(obj *unstructured.Unstructured) (success
 		return false, false, fmt.Errorf("failed to get experiment status: %w", err)
 	}
 	if !found {
-		return false, false, nil
+		return c.evaluateRecords(obj)
 	}
 
 	phase, found, err := unstructured.NestedString(experiment, "phase")
@@ -214,11 +479,15 @@ func (c *Client) checkExperimentStatus(obj *unstructured.Unstructured) (success
 		return false, false, fmt.Errorf("failed to get phase: %w", err)
 	}
 	if !found {
-		return false, false, nil
+		// Chaos Mesh 2.x no longer reports a phase
+		return c.evaluateRecords(obj)
 	}
 
 	c.logger.Debugf("Experiment phase: %s", phase)
 
+	action, _, _ := unstructured.NestedString(obj.Object, "spec", "action")
+	oneShot := traitsOf(obj.GetKind()).oneShot(action)
+
 	switch phase {
 	case "Running":
 		return false, false, nil
@@ -249,7 +518,7 @@ func (c *Client) checkExperimentStatus(obj *unstructured.Unstructured) (success
 				if condType == "AllInjected" && condStatus != "True" {
 					return false, true, nil
 				}
-				if condType == "AllRecovered" && condStatus != "True" {
+				if condType == "AllRecovered" && condStatus != "True" && !oneShot {
 					return false, true, nil
 				}
 			}
@@ -261,59 +530,3 @@ func (c *Client) checkExperimentStatus(obj *unstructured.Unstructured) (success
 		return false, false, nil
 	}
 }
-
-// getGVR returns the GroupVersionResource for a given kind
-func (c *Client) getGVR(kind string) (schema.GroupVersionResource, error) {
-	switch kind {
-	case "PodChaos":
-		return schema.GroupVersionResource{
-			Group:    "chaos-mesh.org",
-			Version:  "v1alpha1",
-			Resource: "podchaos",
-		}, nil
-	case "NetworkChaos":
-		return schema.GroupVersionResource{
-			Group:    "chaos-mesh.org",
-			Version:  "v1alpha1",
-			Resource: "networkchaos",
-		}, nil
-	case "StressChaos":
-		return schema.GroupVersionResource{
-			Group:    "chaos-mesh.org",
-			Version:  "v1alpha1",
-			Resource: "stresschaos",
-		}, nil
-	case "IOChaos":
-		return schema.GroupVersionResource{
-			Group:    "chaos-mesh.org",
-			Version:  "v1alpha1",
-			Resource: "iochaos",
-		}, nil
-	case "TimeChaos":
-		return schema.GroupVersionResource{
-			Group:    "chaos-mesh.org",
-			Version:  "v1alpha1",
-			Resource: "timechaos",
-		}, nil
-	case "KernelChaos":
-		return schema.GroupVersionResource{
-			Group:    "chaos-mesh.org",
-			Version:  "v1alpha1",
-			Resource: "kernelchaos",
-		}, nil
-	case "DNSChaos":
-		return schema.GroupVersionResource{
-			Group:    "chaos-mesh.org",
-			Version:  "v1alpha1",
-			Resource: "dnschaos",
-		}, nil
-	case "HTTPChaos":
-		return schema.GroupVersionResource{
-			Group:    "chaos-mesh.org",
-			Version:  "v1alpha1",
-			Resource: "httpchaos",
-		}, nil
-	default:
-		return schema.GroupVersionResource{}, fmt.Errorf("unsupported chaos kind: %s", kind)
-	}
-}
\ No newline at end of file
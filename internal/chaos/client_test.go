@@ -1,10 +1,16 @@
 package chaos
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -54,7 +60,7 @@ func TestGetGVR(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.kind, func(t *testing.T) {
-			gvr, err := client.getGVR(test.kind)
+			gvr, err := client.getGVR("chaos-mesh.org/v1alpha1", test.kind)
 			
 			if test.hasError {
 				if err == nil {
@@ -104,7 +110,7 @@ func TestInjectSelector(t *testing.T) {
 		"app":                        "test-app",
 	}
 
-	err := client.injectSelector(obj, targetSelector)
+	_, err := client.injectSelector(obj, TargetSelector{Labels: targetSelector})
 	if err != nil {
 		t.Fatalf("Failed to inject selector: %v", err)
 	}
@@ -236,4 +242,209 @@ func TestCheckExperimentStatus(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestGetExperiment(t *testing.T) {
+	experiment := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "chaos-mesh.org/v1alpha1",
+			"kind":       "PodChaos",
+			"metadata": map[string]interface{}{
+				"name":      "test-chaos",
+				"namespace": "default",
+				"uid":       "uid-1",
+			},
+		},
+	}
+
+	client := newFakeClient(t, experiment)
+	ctx := context.Background()
+
+	obj, err := client.GetExperiment(ctx, ExperimentRef{Kind: "PodChaos", Namespace: "default", Name: "test-chaos", UID: "uid-1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if obj.GetName() != "test-chaos" {
+		t.Errorf("Expected experiment test-chaos, got %s", obj.GetName())
+	}
+
+	// An object recreated under the same name is not the experiment we created
+	_, err = client.GetExperiment(ctx, ExperimentRef{Kind: "PodChaos", Namespace: "default", Name: "test-chaos", UID: "uid-2"})
+	if !errors.IsNotFound(err) {
+		t.Errorf("Expected NotFound error for replaced experiment, got %v", err)
+	}
+
+	_, err = client.GetExperiment(ctx, ExperimentRef{Kind: "PodChaos", Namespace: "default", Name: "missing"})
+	if !errors.IsNotFound(err) {
+		t.Errorf("Expected NotFound error for missing experiment, got %v", err)
+	}
+}
+
+func TestFindExperiment(t *testing.T) {
+	tracking := Tracking{
+		AnalysisRunNamespace: "default",
+		AnalysisRunName:      "analysis",
+		AnalysisRunUID:       "run-uid",
+		MetricName:           "chaos-mesh-test",
+	}
+
+	newExperiment := func(name string, created time.Time, labels map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "chaos-mesh.org/v1alpha1",
+				"kind":       "PodChaos",
+				"metadata": map[string]interface{}{
+					"name":              name,
+					"namespace":         "default",
+					"creationTimestamp": created.UTC().Format(time.RFC3339),
+					"labels":            labels,
+				},
+			},
+		}
+	}
+
+	trackingLabels := map[string]interface{}{}
+	for k, v := range tracking.Labels() {
+		trackingLabels[k] = v
+	}
+
+	now := time.Now()
+	client := newFakeClient(t,
+		newExperiment("older", now.Add(-time.Hour), trackingLabels),
+		newExperiment("newer", now, trackingLabels),
+		newExperiment("unrelated", now.Add(time.Hour), map[string]interface{}{"app": "other"}),
+	)
+
+	obj, err := client.FindExperiment(context.Background(), "chaos-mesh.org/v1alpha1", "PodChaos", "", tracking)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if obj.GetName() != "newer" {
+		t.Errorf("Expected newest tracked experiment 'newer', got %s", obj.GetName())
+	}
+
+	tracking.MetricName = "other-metric"
+	_, err = client.FindExperiment(context.Background(), "chaos-mesh.org/v1alpha1", "PodChaos", "", tracking)
+	if !errors.IsNotFound(err) {
+		t.Errorf("Expected NotFound error when no experiment is tracked, got %v", err)
+	}
+}
+
+// listKinds maps the chaos resources, Events and Pods used in tests to their list kinds for the fake client
+var listKinds = func() map[schema.GroupVersionResource]string {
+	kinds := map[schema.GroupVersionResource]string{}
+	for kind, resource := range chaosResources {
+		kinds[schema.GroupVersionResource{Group: "chaos-mesh.org", Version: "v1alpha1", Resource: resource}] = kind + "List"
+	}
+	kinds[eventGVR] = "EventList"
+	kinds[podGVR] = "PodList"
+	return kinds
+}()
+
+// newFakeClient returns a Client backed by a fake dynamic client seeded with objects.
+// Objects are created through their real resource so lookups by GVR find them.
+func newFakeClient(t *testing.T, objects ...*unstructured.Unstructured) *Client {
+	client := &Client{
+		dynamicClient: fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds),
+		logger:        *log.WithFields(log.Fields{"test": "chaos"}),
+	}
+
+	for _, obj := range objects {
+		gvr, err := client.getGVR(obj.GetAPIVersion(), obj.GetKind())
+		if err != nil {
+			t.Fatalf("Failed to get GVR for kind %s: %v", obj.GetKind(), err)
+		}
+		_, err = client.dynamicClient.Resource(gvr).Namespace(obj.GetNamespace()).Create(context.Background(), obj, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Failed to seed object %s: %v", obj.GetName(), err)
+		}
+	}
+
+	return client
+}
+
+func TestDeleteOldExperiments(t *testing.T) {
+	tracking := Tracking{
+		AnalysisRunNamespace: "default",
+		AnalysisRunName:      "analysis",
+		AnalysisRunUID:       "run-uid",
+		MetricName:           "chaos-mesh-test",
+	}
+	trackingLabels := map[string]interface{}{}
+	for k, v := range tracking.Labels() {
+		trackingLabels[k] = v
+	}
+
+	newExperiment := func(kind, name string, created time.Time) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "chaos-mesh.org/v1alpha1",
+				"kind":       kind,
+				"metadata": map[string]interface{}{
+					"name":              name,
+					"namespace":         "default",
+					"creationTimestamp": created.UTC().Format(time.RFC3339),
+					"labels":            trackingLabels,
+				},
+			},
+		}
+	}
+
+	now := time.Now()
+	client := newFakeClient(t,
+		newExperiment("PodChaos", "oldest", now.Add(-3*time.Hour)),
+		newExperiment("NetworkChaos", "older", now.Add(-2*time.Hour)),
+		newExperiment("PodChaos", "newer", now.Add(-time.Hour)),
+		newExperiment("StressChaos", "newest", now),
+	)
+	ctx := context.Background()
+
+	deleted, err := client.DeleteOldExperiments(ctx, tracking.Selector(), 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 experiments to be deleted, got %d", deleted)
+	}
+
+	remaining, err := client.ListExperiments(ctx, tracking.Selector())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	names := map[string]bool{}
+	for _, item := range remaining {
+		names[item.GetName()] = true
+	}
+	if len(names) != 2 || !names["newer"] || !names["newest"] {
+		t.Errorf("Expected 'newer' and 'newest' to remain, got %v", names)
+	}
+}
+
+func TestPauseExperiment(t *testing.T) {
+	experiment := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "chaos-mesh.org/v1alpha1",
+			"kind":       "PodChaos",
+			"metadata": map[string]interface{}{
+				"name":      "test-chaos",
+				"namespace": "default",
+				"uid":       "uid-1",
+			},
+		},
+	}
+
+	client := newFakeClient(t, experiment)
+	ctx := context.Background()
+	ref := ExperimentRef{Kind: "PodChaos", Namespace: "default", Name: "test-chaos", UID: "uid-1"}
+
+	if err := client.PauseExperiment(ctx, ref); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	obj, err := client.GetExperiment(ctx, ref)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if obj.GetAnnotations()[AnnotationPause] != "true" {
+		t.Errorf("Expected %s annotation to be 'true', got %v", AnnotationPause, obj.GetAnnotations())
+	}
+}
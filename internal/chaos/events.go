@@ -0,0 +1,197 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Event types, as understood by kubectl describe
+const (
+	EventTypeNormal  = "Normal"
+	EventTypeWarning = "Warning"
+)
+
+// maxEventMessage is the longest Event message recorded, matching the limit the events API
+// puts on the note of an Event
+const maxEventMessage = 1024
+
+// Limits keeping the summary of the controller Events short enough for a measurement message
+const (
+	// maxSummaryEvents is the number of distinct Events summarised
+	maxSummaryEvents = 3
+	// maxSummaryMessage is the length of each summarised Event message
+	maxSummaryMessage = 200
+)
+
+// eventGVR is the resource of core Kubernetes Events
+var eventGVR = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+
+// EventObject identifies the object an Event is recorded against
+type EventObject struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	UID        types.UID
+}
+
+// RecordEvent records a Kubernetes Event against object, so it shows up in kubectl describe
+func (c *Client) RecordEvent(ctx context.Context, object EventObject, eventType, reason, message string) error {
+	if len(message) > maxEventMessage {
+		message = message[:maxEventMessage-3] + "..."
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	event := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Event",
+		"metadata": map[string]interface{}{
+			// Like client-go, name the Event after the object and the time it was recorded
+			"name":      fmt.Sprintf("%s.%x", object.Name, time.Now().UnixNano()),
+			"namespace": object.Namespace,
+		},
+		"involvedObject": map[string]interface{}{
+			"apiVersion": object.APIVersion,
+			"kind":       object.Kind,
+			"namespace":  object.Namespace,
+			"name":       object.Name,
+			"uid":        string(object.UID),
+		},
+		"type":               eventType,
+		"reason":             reason,
+		"message":            message,
+		"source":             map[string]interface{}{"component": ManagedByValue},
+		"reportingComponent": ManagedByValue,
+		"firstTimestamp":     now,
+		"lastTimestamp":      now,
+		"count":              int64(1),
+	}}
+
+	if _, err := c.dynamicClient.Resource(eventGVR).Namespace(object.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to record event %s on %s %s/%s: %w", reason, object.Kind, object.Namespace, object.Name, err)
+	}
+	return nil
+}
+
+// ControllerEvent is an Event recorded on an experiment, e.g. by the chaos-controller-manager
+type ControllerEvent struct {
+	Type    string
+	Reason  string
+	Message string
+	// Count is how many times the Event occurred
+	Count int64
+	// LastSeen is when the Event last occurred
+	LastSeen time.Time
+}
+
+// ExperimentEvents lists the Events recorded on the experiment, most recent first
+func (c *Client) ExperimentEvents(ctx context.Context, ref ExperimentRef) ([]ControllerEvent, error) {
+	list, err := c.dynamicClient.Resource(eventGVR).Namespace(ref.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.SelectorFromSet(fields.Set{
+			"involvedObject.kind": ref.Kind,
+			"involvedObject.name": ref.Name,
+		}).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events of experiment %s: %w", ref, err)
+	}
+
+	var events []ControllerEvent
+	for _, item := range list.Items {
+		involved, _, _ := unstructured.NestedStringMap(item.Object, "involvedObject")
+		// Not every API server honours the field selector, and a recreated experiment keeps
+		// the name but not the UID
+		if involved["kind"] != ref.Kind || involved["name"] != ref.Name || (ref.UID != "" && involved["uid"] != "" && involved["uid"] != string(ref.UID)) {
+			continue
+		}
+
+		event := ControllerEvent{Count: 1}
+		event.Type, _, _ = unstructured.NestedString(item.Object, "type")
+		event.Reason, _, _ = unstructured.NestedString(item.Object, "reason")
+		event.Message, _, _ = unstructured.NestedString(item.Object, "message")
+		if count, found, _ := unstructured.NestedInt64(item.Object, "count"); found && count > 0 {
+			event.Count = count
+		}
+		event.LastSeen = nestedTime(item.Object, "lastTimestamp")
+		if event.LastSeen.IsZero() {
+			event.LastSeen = nestedTime(item.Object, "eventTime")
+		}
+		if event.LastSeen.IsZero() {
+			event.LastSeen = item.GetCreationTimestamp().Time
+		}
+		events = append(events, event)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].LastSeen.After(events[j].LastSeen) })
+	return events, nil
+}
+
+// selectionFailedMessage starts the message of the Warning Event the chaos-controller-manager
+// records when it fails to select the targets of an experiment, e.g.
+// "Failed to select targets: no pod is selected"
+const selectionFailedMessage = "failed to select"
+
+// SelectionFailed reports whether the chaos-controller-manager recorded a Warning Event on the
+// experiment because it failed to select its targets
+func (c *Client) SelectionFailed(ctx context.Context, ref ExperimentRef) (bool, error) {
+	events, err := c.ExperimentEvents(ctx, ref)
+	if err != nil {
+		return false, err
+	}
+	for _, event := range events {
+		if event.Type == EventTypeWarning && strings.HasPrefix(strings.ToLower(event.Message), selectionFailedMessage) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SummarizeEvents summarises the warnings among events, most recent first, e.g.
+// "Failed: Failed to apply chaos: no pod is selected (3 times)". Repeated warnings are
+// reported once and at most maxSummaryEvents distinct warnings are kept.
+func SummarizeEvents(events []ControllerEvent) string {
+	type warning struct {
+		reason, message string
+	}
+	counts := make(map[warning]int64)
+	var order []warning
+	for _, event := range events {
+		if event.Type != EventTypeWarning {
+			continue
+		}
+		key := warning{reason: event.Reason, message: strings.TrimSpace(event.Message)}
+		if _, seen := counts[key]; !seen {
+			order = append(order, key)
+		}
+		counts[key] += event.Count
+	}
+
+	var parts []string
+	for _, key := range order[:min(len(order), maxSummaryEvents)] {
+		message := key.message
+		if runes := []rune(message); len(runes) > maxSummaryMessage {
+			message = string(runes[:maxSummaryMessage-3]) + "..."
+		}
+		part := message
+		if key.reason != "" {
+			part = key.reason + ": " + message
+		}
+		if counts[key] > 1 {
+			part += fmt.Sprintf(" (%d times)", counts[key])
+		}
+		parts = append(parts, part)
+	}
+	if len(order) > maxSummaryEvents {
+		parts = append(parts, fmt.Sprintf("%d more warnings", len(order)-maxSummaryEvents))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,141 @@
+package chaos
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRecordEvent(t *testing.T) {
+	client := newFakeClient(t)
+	object := EventObject{APIVersion: "argoproj.io/v1alpha1", Kind: "AnalysisRun", Namespace: "rollouts", Name: "analysis", UID: "run-uid"}
+
+	message := strings.Repeat("x", 2*maxEventMessage)
+	if err := client.RecordEvent(context.Background(), object, EventTypeWarning, "ChaosExperimentFailed", message); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	events, err := client.dynamicClient.Resource(eventGVR).Namespace("rollouts").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	if len(events.Items) != 1 {
+		t.Fatalf("Expected one event, got %d", len(events.Items))
+	}
+	event := events.Items[0]
+
+	for _, field := range []struct {
+		path     []string
+		expected string
+	}{
+		{[]string{"involvedObject", "kind"}, "AnalysisRun"},
+		{[]string{"involvedObject", "name"}, "analysis"},
+		{[]string{"involvedObject", "uid"}, "run-uid"},
+		{[]string{"type"}, EventTypeWarning},
+		{[]string{"reason"}, "ChaosExperimentFailed"},
+		{[]string{"source", "component"}, ManagedByValue},
+	} {
+		value, _, _ := unstructured.NestedString(event.Object, field.path...)
+		if value != field.expected {
+			t.Errorf("Expected %s to be %q, got %q", strings.Join(field.path, "."), field.expected, value)
+		}
+	}
+	recorded, _, _ := unstructured.NestedString(event.Object, "message")
+	if len(recorded) != maxEventMessage || !strings.HasSuffix(recorded, "...") {
+		t.Errorf("Expected the message to be truncated to %d bytes, got %d", maxEventMessage, len(recorded))
+	}
+}
+
+func newEvent(name, kind, object, uid, eventType, message, lastSeen string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion":     "v1",
+		"kind":           "Event",
+		"metadata":       map[string]interface{}{"name": name, "namespace": "default"},
+		"involvedObject": map[string]interface{}{"kind": kind, "name": object, "namespace": "default", "uid": uid},
+		"type":           eventType,
+		"reason":         "Failed",
+		"message":        message,
+		"lastTimestamp":  lastSeen,
+	}}
+}
+
+func TestExperimentEvents(t *testing.T) {
+	client := newFakeClient(t)
+	for _, event := range []*unstructured.Unstructured{
+		newEvent("older", "PodChaos", "test-chaos", "uid-1", EventTypeWarning, "older", "2024-01-01T10:00:00Z"),
+		newEvent("newer", "PodChaos", "test-chaos", "uid-1", EventTypeWarning, "newer", "2024-01-01T11:00:00Z"),
+		newEvent("other-kind", "NetworkChaos", "test-chaos", "uid-2", EventTypeWarning, "other kind", "2024-01-01T12:00:00Z"),
+		newEvent("recreated", "PodChaos", "test-chaos", "uid-0", EventTypeWarning, "previous experiment", "2024-01-01T12:00:00Z"),
+	} {
+		if _, err := client.dynamicClient.Resource(eventGVR).Namespace("default").Create(context.Background(), event, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+	}
+
+	ref := ExperimentRef{Kind: "PodChaos", Namespace: "default", Name: "test-chaos", UID: "uid-1"}
+	events, err := client.ExperimentEvents(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(events) != 2 || events[0].Message != "newer" || events[1].Message != "older" {
+		t.Errorf("Expected the events of the experiment, most recent first, got %+v", events)
+	}
+}
+
+func TestSummarizeEvents(t *testing.T) {
+	warning := func(reason, message string, count int64) ControllerEvent {
+		return ControllerEvent{Type: EventTypeWarning, Reason: reason, Message: message, Count: count}
+	}
+
+	tests := []struct {
+		name     string
+		events   []ControllerEvent
+		expected string
+	}{
+		{
+			name:     "No events",
+			expected: "",
+		},
+		{
+			name:     "Only normal events",
+			events:   []ControllerEvent{{Type: EventTypeNormal, Reason: "Applied", Message: "Successfully apply chaos", Count: 1}},
+			expected: "",
+		},
+		{
+			name: "Repeated warnings are merged",
+			events: []ControllerEvent{
+				warning("Failed", "Failed to apply chaos: no pod is selected", 2),
+				{Type: EventTypeNormal, Reason: "Started", Message: "Experiment has started", Count: 1},
+				warning("Failed", "Failed to apply chaos: no pod is selected", 1),
+			},
+			expected: "Failed: Failed to apply chaos: no pod is selected (3 times)",
+		},
+		{
+			name: "Distinct warnings are capped",
+			events: []ControllerEvent{
+				warning("Failed", "first", 1),
+				warning("Failed", "second", 1),
+				warning("Failed", "third", 1),
+				warning("Failed", "fourth", 1),
+				warning("Failed", "fifth", 1),
+			},
+			expected: "Failed: first; Failed: second; Failed: third; 2 more warnings",
+		},
+		{
+			name:     "Long messages are truncated",
+			events:   []ControllerEvent{warning("", strings.Repeat("x", 2*maxSummaryMessage), 1)},
+			expected: strings.Repeat("x", maxSummaryMessage-3) + "...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if summary := SummarizeEvents(tt.events); summary != tt.expected {
+				t.Errorf("Expected summary '%s', got '%s'", tt.expected, summary)
+			}
+		})
+	}
+}
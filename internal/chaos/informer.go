@@ -0,0 +1,177 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// informerSyncTimeout is how long WatchExperiment waits for a shared informer to list its
+// experiments before watching the experiment on its own
+var informerSyncTimeout = 30 * time.Second
+
+// informerKey identifies the experiments one shared informer caches
+type informerKey struct {
+	resource  schema.GroupVersionResource
+	namespace string
+}
+
+// informerHub shares one informer, and so one watch stream, per chaos kind and namespace
+// between every experiment the plugin is waiting for. Informers start with their first
+// subscriber and stop with their last one. The zero value is ready to use.
+type informerHub struct {
+	mu        sync.Mutex
+	informers map[informerKey]*sharedInformer
+}
+
+// sharedInformer is an informer with the subscriptions it notifies
+type sharedInformer struct {
+	informer cache.SharedIndexInformer
+	stop     chan struct{}
+
+	mu            sync.Mutex
+	subscriptions map[*subscription]struct{}
+}
+
+// subscription is the interest of one watcher in one experiment of a shared informer
+type subscription struct {
+	shared   *sharedInformer
+	key      informerKey
+	resource schema.GroupResource
+	ref      ExperimentRef
+	// name is the namespace/name cache key of the experiment
+	name string
+	// changed receives a value whenever the experiment may have changed
+	changed chan struct{}
+}
+
+// subscribe registers interest in the experiment, starting the informer of its kind and
+// namespace if none is running yet
+func (h *informerHub) subscribe(client dynamic.Interface, resource schema.GroupVersionResource, ref ExperimentRef) *subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := informerKey{resource: resource, namespace: ref.Namespace}
+	shared, exists := h.informers[key]
+	if !exists {
+		shared = newSharedInformer(client, key)
+		if h.informers == nil {
+			h.informers = make(map[informerKey]*sharedInformer)
+		}
+		h.informers[key] = shared
+	}
+
+	sub := &subscription{
+		shared:   shared,
+		key:      key,
+		resource: resource.GroupResource(),
+		ref:      ref,
+		name:     ref.Namespace + "/" + ref.Name,
+		changed:  make(chan struct{}, 1),
+	}
+	shared.mu.Lock()
+	shared.subscriptions[sub] = struct{}{}
+	shared.mu.Unlock()
+	return sub
+}
+
+// unsubscribe removes the subscription, stopping its informer when it was the last one
+func (h *informerHub) unsubscribe(sub *subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	shared := sub.shared
+	shared.mu.Lock()
+	delete(shared.subscriptions, sub)
+	idle := len(shared.subscriptions) == 0
+	shared.mu.Unlock()
+
+	if idle && h.informers[sub.key] == shared {
+		close(shared.stop)
+		delete(h.informers, sub.key)
+	}
+}
+
+// active returns the number of running informers
+func (h *informerHub) active() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.informers)
+}
+
+// newSharedInformer starts an informer for the experiments of key. It only lists and
+// watches the experiments created by the plugin, so the cache does not hold every
+// experiment of the namespace.
+func newSharedInformer(client dynamic.Interface, key informerKey) *sharedInformer {
+	managedBy := func(options *metav1.ListOptions) {
+		options.LabelSelector = labels.SelectorFromSet(labels.Set{LabelManagedBy: ManagedByValue}).String()
+	}
+	shared := &sharedInformer{
+		informer:      dynamicinformer.NewFilteredDynamicInformer(client, key.resource, key.namespace, 0, cache.Indexers{}, managedBy).Informer(),
+		stop:          make(chan struct{}),
+		subscriptions: make(map[*subscription]struct{}),
+	}
+	// Adding a handler only fails once the informer stopped, which it has not even started yet
+	_, _ = shared.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    shared.notify,
+		UpdateFunc: func(_, obj interface{}) { shared.notify(obj) },
+		DeleteFunc: shared.notify,
+	})
+	go shared.informer.Run(shared.stop)
+	return shared
+}
+
+// notify wakes up the subscriptions to the changed object
+func (s *sharedInformer) notify(obj interface{}) {
+	name, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subscriptions {
+		if sub.name != name {
+			continue
+		}
+		// A pending notification already covers this change
+		select {
+		case sub.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// waitForSync waits until the informer listed its experiments, giving up after timeout
+func (sub *subscription) waitForSync(ctx context.Context, timeout time.Duration) bool {
+	syncCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return cache.WaitForCacheSync(syncCtx.Done(), sub.shared.informer.HasSynced)
+}
+
+// get returns a copy of the cached experiment
+func (sub *subscription) get() (*unstructured.Unstructured, error) {
+	item, exists, err := sub.shared.informer.GetStore().GetByKey(sub.name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read experiment from cache: %w", err)
+	}
+	obj, ok := item.(*unstructured.Unstructured)
+	if exists && !ok {
+		return nil, fmt.Errorf("unexpected object %T in cache", item)
+	}
+	// An object with another UID replaced the experiment
+	if !exists || (sub.ref.UID != "" && obj.GetUID() != sub.ref.UID) {
+		return nil, errors.NewNotFound(sub.resource, sub.ref.Name)
+	}
+	return obj.DeepCopy(), nil
+}
@@ -0,0 +1,149 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// waitForWatches waits until the fake client served count watches
+func waitForWatches(t *testing.T, dynamicClient *fake.FakeDynamicClient, count int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for countActions(dynamicClient, "watch") < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d watches", count)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWatchExperimentSharesInformer(t *testing.T) {
+	other := newWatchedExperiment("Running", "1")
+	other.SetName("other-chaos")
+	client := newFakeClient(t, newWatchedExperiment("Running", "1"), other)
+	dynamicClient := client.dynamicClient.(*fake.FakeDynamicClient)
+	otherRef := watchedRef
+	otherRef.Name = "other-chaos"
+
+	type result struct {
+		obj *unstructured.Unstructured
+		err error
+	}
+	results := make(chan result, 2)
+	for _, ref := range []ExperimentRef{watchedRef, otherRef} {
+		go func(ref ExperimentRef) {
+			obj, err := client.WatchExperiment(context.Background(), ref, 5*time.Second)
+			results <- result{obj: obj, err: err}
+		}(ref)
+	}
+
+	waitForWatches(t, dynamicClient, 1)
+	otherFinished := newWatchedExperiment("Finished", "2")
+	otherFinished.SetName("other-chaos")
+	for _, experiment := range []*unstructured.Unstructured{newWatchedExperiment("Finished", "2"), otherFinished} {
+		if err := dynamicClient.Tracker().Update(podChaosGVR, experiment, "default"); err != nil {
+			t.Fatalf("Failed to update experiment: %v", err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		res := <-results
+		if res.err != nil {
+			t.Fatalf("Unexpected error: %v", res.err)
+		}
+		phase, _, _ := unstructured.NestedString(res.obj.Object, "status", "experiment", "phase")
+		if phase != "Finished" {
+			t.Errorf("Expected the finished experiment, got phase %s", phase)
+		}
+	}
+
+	if watches := countActions(dynamicClient, "watch"); watches != 1 {
+		t.Errorf("Expected both experiments to share one watch, got %d watches", watches)
+	}
+	if active := client.informers.active(); active != 0 {
+		t.Errorf("Expected the informer to stop with its last subscriber, got %d running", active)
+	}
+}
+
+func TestWatchExperimentSharedInformerDeleted(t *testing.T) {
+	client := newFakeClient(t, newWatchedExperiment("Running", "1"))
+	dynamicClient := client.dynamicClient.(*fake.FakeDynamicClient)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := client.WatchExperiment(context.Background(), watchedRef, 5*time.Second)
+		errs <- err
+	}()
+
+	waitForWatches(t, dynamicClient, 1)
+	if err := dynamicClient.Tracker().Delete(podChaosGVR, "default", "test-chaos"); err != nil {
+		t.Fatalf("Failed to delete experiment: %v", err)
+	}
+
+	if err := <-errs; !errors.IsNotFound(err) {
+		t.Errorf("Expected NotFound once the experiment is deleted, got %v", err)
+	}
+}
+
+func TestWatchExperimentSharedInformerTimeout(t *testing.T) {
+	client := newFakeClient(t, newWatchedExperiment("Running", "1"))
+
+	_, err := client.WatchExperiment(context.Background(), watchedRef, 50*time.Millisecond)
+	if err == nil || err.Error() != "timeout waiting for experiment to complete" {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+	if active := client.informers.active(); active != 0 {
+		t.Errorf("Expected the informer to stop after the timeout, got %d running", active)
+	}
+}
+
+func TestWatchExperimentSharedInformerOnlyCachesManagedExperiments(t *testing.T) {
+	unmanaged := newWatchedExperiment("Running", "1")
+	unmanaged.SetName("other-chaos")
+	unmanaged.SetLabels(nil)
+	client := newFakeClient(t, newWatchedExperiment("Running", "1"), unmanaged)
+	dynamicClient := client.dynamicClient.(*fake.FakeDynamicClient)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := client.WatchExperiment(context.Background(), watchedRef, 5*time.Second)
+		errs <- err
+	}()
+
+	waitForWatches(t, dynamicClient, 1)
+	selector := LabelManagedBy + "=" + ManagedByValue
+	for _, action := range dynamicClient.Actions() {
+		var got string
+		switch action := action.(type) {
+		case k8stesting.ListActionImpl:
+			got = action.GetListRestrictions().Labels.String()
+		case k8stesting.WatchActionImpl:
+			got = action.GetWatchRestrictions().Labels.String()
+		default:
+			continue
+		}
+		if got != selector {
+			t.Errorf("Expected the informer to %s with selector %s, got %q", action.GetVerb(), selector, got)
+		}
+	}
+
+	client.informers.mu.Lock()
+	shared := client.informers.informers[informerKey{resource: podChaosGVR, namespace: "default"}]
+	client.informers.mu.Unlock()
+	if keys := shared.informer.GetStore().ListKeys(); len(keys) != 1 || keys[0] != "default/test-chaos" {
+		t.Errorf("Expected only the plugin-created experiment in the cache, got %v", keys)
+	}
+
+	if err := dynamicClient.Tracker().Update(podChaosGVR, newWatchedExperiment("Finished", "2"), "default"); err != nil {
+		t.Fatalf("Failed to update experiment: %v", err)
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package chaos
+
+// kindTraits describes how the plugin treats the experiments of a chaos kind
+type kindTraits struct {
+	// selectsPods reports whether the kind targets pods through spec.selector, so the target
+	// selector of the ReplicaSet can be injected into it
+	selectsPods bool
+	// oneShotActions are actions that act once, e.g. restarting an instance, and leave
+	// nothing to recover. Their experiments succeed once the fault was injected.
+	oneShotActions []string
+}
+
+// chaosKindTraits holds the kinds that differ from pod-targeting experiments with a recovery.
+// Kinds missing from the table, including custom kinds found through discovery, select pods
+// and recover every action.
+var chaosKindTraits = map[string]kindTraits{
+	// Killed pods and containers are restarted by Kubernetes, not recovered by Chaos Mesh
+	"PodChaos": {selectsPods: true, oneShotActions: []string{"pod-kill", "container-kill"}},
+	// PhysicalMachineChaos selects PhysicalMachine objects or addresses rather than pods
+	"PhysicalMachineChaos": {selectsPods: false},
+	// Cloud kinds target instances and disks by id and have no selector at all
+	"AWSChaos":   {selectsPods: false, oneShotActions: []string{"ec2-restart"}},
+	"GCPChaos":   {selectsPods: false, oneShotActions: []string{"node-reset"}},
+	"AzureChaos": {selectsPods: false, oneShotActions: []string{"vm-restart"}},
+}
+
+// traitsOf returns the traits of a chaos kind
+func traitsOf(kind string) kindTraits {
+	if traits, ok := chaosKindTraits[kind]; ok {
+		return traits
+	}
+	return kindTraits{selectsPods: true}
+}
+
+// oneShot reports whether action leaves nothing to recover
+func (t kindTraits) oneShot(action string) bool {
+	for _, oneShot := range t.oneShotActions {
+		if action == oneShot {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package chaos
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGetGVRRemainingKinds(t *testing.T) {
+	client := &Client{
+		logger: *log.WithFields(log.Fields{"test": "chaos"}),
+	}
+
+	tests := map[string]string{
+		"JVMChaos":             "jvmchaos",
+		"BlockChaos":           "blockchaos",
+		"PhysicalMachineChaos": "physicalmachinechaos",
+		"AWSChaos":             "awschaos",
+		"GCPChaos":             "gcpchaos",
+		"AzureChaos":           "azurechaos",
+	}
+	for kind, resource := range tests {
+		gvr, err := client.getGVR("chaos-mesh.org/v1alpha1", kind)
+		if err != nil {
+			t.Errorf("Unexpected error for kind %s: %v", kind, err)
+			continue
+		}
+		if gvr.Resource != resource {
+			t.Errorf("Expected resource %s for kind %s, got %s", resource, kind, gvr.Resource)
+		}
+	}
+}
+
+func TestInjectSelectorByKind(t *testing.T) {
+	client := &Client{
+		logger: *log.WithFields(log.Fields{"test": "chaos"}),
+	}
+
+	tests := []struct {
+		kind     string
+		spec     map[string]interface{}
+		injected bool
+	}{
+		{
+			kind:     "JVMChaos",
+			spec:     map[string]interface{}{"action": "latency", "mode": "all", "selector": map[string]interface{}{}},
+			injected: true,
+		},
+		{
+			kind:     "BlockChaos",
+			spec:     map[string]interface{}{"action": "delay", "mode": "one", "volumeName": "data"},
+			injected: true,
+		},
+		{
+			kind:     "PhysicalMachineChaos",
+			spec:     map[string]interface{}{"action": "stress-cpu", "mode": "one", "address": []interface{}{"192.168.0.10:31767"}},
+			injected: false,
+		},
+		{
+			kind:     "AWSChaos",
+			spec:     map[string]interface{}{"action": "ec2-stop", "ec2Instance": "i-0123", "awsRegion": "us-east-1"},
+			injected: false,
+		},
+		{
+			kind:     "GCPChaos",
+			spec:     map[string]interface{}{"action": "node-stop", "project": "p", "zone": "z", "instance": "i"},
+			injected: false,
+		},
+		{
+			kind:     "AzureChaos",
+			spec:     map[string]interface{}{"action": "vm-stop", "subscriptionID": "s", "resourceGroupName": "g", "vmName": "vm"},
+			injected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "chaos-mesh.org/v1alpha1",
+				"kind":       tt.kind,
+				"metadata":   map[string]interface{}{"name": "test-chaos", "namespace": "default"},
+				"spec":       tt.spec,
+			}}
+			if _, err := client.injectSelector(obj, TargetSelector{Labels: map[string]string{"app": "test-app"}}); err != nil {
+				t.Fatalf("Failed to inject selector: %v", err)
+			}
+
+			_, found, _ := unstructured.NestedString(obj.Object, "spec", "selector", "labelSelectors", "app")
+			if found != tt.injected {
+				t.Errorf("Expected selector injected=%v for %s, got spec %v", tt.injected, tt.kind, obj.Object["spec"])
+			}
+		})
+	}
+}
+
+func TestCheckExperimentStatusOneShot(t *testing.T) {
+	client := &Client{
+		logger: *log.WithFields(log.Fields{"test": "chaos"}),
+	}
+
+	tests := []struct {
+		kind            string
+		action          string
+		expectedSuccess bool
+	}{
+		{kind: "AWSChaos", action: "ec2-restart", expectedSuccess: true},
+		{kind: "AWSChaos", action: "ec2-stop", expectedSuccess: false},
+		{kind: "GCPChaos", action: "node-reset", expectedSuccess: true},
+		{kind: "AzureChaos", action: "vm-restart", expectedSuccess: true},
+		{kind: "JVMChaos", action: "exception", expectedSuccess: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind+"/"+tt.action, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{
+				"kind": tt.kind,
+				"spec": map[string]interface{}{"action": tt.action},
+				"status": map[string]interface{}{
+					"experiment": map[string]interface{}{"phase": "Finished"},
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "AllInjected", "status": "True"},
+						map[string]interface{}{"type": "AllRecovered", "status": "False"},
+					},
+				},
+			}}
+
+			success, finished, err := client.checkExperimentStatus(obj)
+			if err != nil || !finished {
+				t.Fatalf("Expected a finished experiment, got finished=%v err=%v", finished, err)
+			}
+			if success != tt.expectedSuccess {
+				t.Errorf("Expected success %v, got %v", tt.expectedSuccess, success)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// LabelCrossNamespaceOwner marks experiments whose AnalysisRun lives in another namespace.
+// Kubernetes garbage collection cannot follow owner references across namespaces, so these
+// experiments are cleaned up by DeleteOrphanedExperiments instead.
+const LabelCrossNamespaceOwner = trackingPrefix + "cross-namespace-owner"
+
+// analysisRunGVR is the resource of the Argo Rollouts AnalysisRun that owns experiments
+var analysisRunGVR = schema.GroupVersionResource{
+	Group:    "argoproj.io",
+	Version:  "v1alpha1",
+	Resource: "analysisruns",
+}
+
+// setOwner links obj to the AnalysisRun with an owner reference when both live in the same
+// namespace, and marks it for the orphan tracker otherwise
+func (t Tracking) setOwner(obj *unstructured.Unstructured, namespace string) {
+	if t.AnalysisRunUID == "" || t.AnalysisRunName == "" {
+		return
+	}
+
+	if namespace != t.AnalysisRunNamespace {
+		objLabels := obj.GetLabels()
+		if objLabels == nil {
+			objLabels = make(map[string]string)
+		}
+		objLabels[LabelCrossNamespaceOwner] = "true"
+		obj.SetLabels(objLabels)
+		return
+	}
+
+	obj.SetOwnerReferences(append(obj.GetOwnerReferences(), metav1.OwnerReference{
+		APIVersion: analysisRunGVR.GroupVersion().String(),
+		Kind:       "AnalysisRun",
+		Name:       t.AnalysisRunName,
+		UID:        types.UID(t.AnalysisRunUID),
+	}))
+}
+
+// DeleteOrphanedExperiments deletes cross-namespace experiments whose owning AnalysisRun no
+// longer exists and returns how many were deleted
+func (c *Client) DeleteOrphanedExperiments(ctx context.Context) (int, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		LabelManagedBy:           ManagedByValue,
+		LabelCrossNamespaceOwner: "true",
+	})
+	experiments, err := c.ListExperiments(ctx, selector.String())
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for i := range experiments {
+		experiment := &experiments[i]
+		orphaned, err := c.isOrphaned(ctx, experiment)
+		if err != nil {
+			c.logger.Warnf("Failed to check owner of experiment %s/%s: %v", experiment.GetNamespace(), experiment.GetName(), err)
+			continue
+		}
+		if !orphaned {
+			continue
+		}
+
+		c.logger.Infof("Owner of experiment %s/%s is gone, deleting it", experiment.GetNamespace(), experiment.GetName())
+		if err := c.DeleteExperiment(ctx, RefFor(experiment)); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// isOrphaned reports whether the AnalysisRun recorded on an experiment no longer exists
+func (c *Client) isOrphaned(ctx context.Context, experiment *unstructured.Unstructured) (bool, error) {
+	owner := experiment.GetAnnotations()[AnnotationAnalysisRun]
+	namespace, name, found := strings.Cut(owner, "/")
+	if !found || name == "" {
+		return false, fmt.Errorf("invalid %s annotation %q", AnnotationAnalysisRun, owner)
+	}
+
+	analysisRun, err := c.dynamicClient.Resource(analysisRunGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// An AnalysisRun recreated under the same name is a different owner
+	return labelValue(string(analysisRun.GetUID())) != experiment.GetLabels()[LabelAnalysisRunUID], nil
+}
@@ -0,0 +1,86 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSetOwner(t *testing.T) {
+	tracking := Tracking{
+		AnalysisRunNamespace: "team-a",
+		AnalysisRunName:      "analysis",
+		AnalysisRunUID:       "run-uid",
+		MetricName:           "chaos-mesh-test",
+	}
+
+	sameNamespace := &unstructured.Unstructured{}
+	tracking.setOwner(sameNamespace, "team-a")
+	owners := sameNamespace.GetOwnerReferences()
+	if len(owners) != 1 || owners[0].Kind != "AnalysisRun" || owners[0].UID != "run-uid" {
+		t.Errorf("Expected owner reference to the AnalysisRun, got %+v", owners)
+	}
+
+	otherNamespace := &unstructured.Unstructured{}
+	tracking.setOwner(otherNamespace, "chaos-testing")
+	if len(otherNamespace.GetOwnerReferences()) != 0 {
+		t.Errorf("Expected no owner reference across namespaces, got %+v", otherNamespace.GetOwnerReferences())
+	}
+	if otherNamespace.GetLabels()[LabelCrossNamespaceOwner] != "true" {
+		t.Errorf("Expected %s label on cross-namespace experiment", LabelCrossNamespaceOwner)
+	}
+}
+
+func TestDeleteOrphanedExperiments(t *testing.T) {
+	newExperiment := func(name, analysisRun, uid string) *unstructured.Unstructured {
+		tracking := Tracking{
+			AnalysisRunNamespace: "team-a",
+			AnalysisRunName:      analysisRun,
+			AnalysisRunUID:       uid,
+			MetricName:           "chaos-mesh-test",
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("chaos-mesh.org/v1alpha1")
+		obj.SetKind("PodChaos")
+		obj.SetNamespace("chaos-testing")
+		obj.SetName(name)
+		tracking.apply(obj)
+		tracking.setOwner(obj, obj.GetNamespace())
+		return obj
+	}
+
+	client := newFakeClient(t,
+		newExperiment("owned", "live", "live-uid"),
+		newExperiment("orphaned", "deleted", "deleted-uid"),
+		newExperiment("replaced", "live", "old-uid"),
+	)
+
+	analysisRun := &unstructured.Unstructured{}
+	analysisRun.SetAPIVersion("argoproj.io/v1alpha1")
+	analysisRun.SetKind("AnalysisRun")
+	analysisRun.SetNamespace("team-a")
+	analysisRun.SetName("live")
+	analysisRun.SetUID("live-uid")
+	ctx := context.Background()
+	if _, err := client.dynamicClient.Resource(analysisRunGVR).Namespace("team-a").Create(ctx, analysisRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to seed AnalysisRun: %v", err)
+	}
+
+	deleted, err := client.DeleteOrphanedExperiments(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 orphaned experiments to be deleted, got %d", deleted)
+	}
+
+	remaining, err := client.ListExperiments(ctx, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].GetName() != "owned" {
+		t.Errorf("Expected only 'owned' to remain, got %d experiments", len(remaining))
+	}
+}
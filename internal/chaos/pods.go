@@ -0,0 +1,63 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// podGVR is the resource of core Kubernetes Pods
+var podGVR = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+
+// TargetNamespaces returns the namespaces, sorted, of the pod selectors of the experiment the
+// target labels are injected into, as CreateExperiment would set them. Experiments selecting
+// no pods, e.g. cloud chaos kinds or PhysicalMachineChaos, have none.
+func (c *Client) TargetNamespaces(experimentYAML string, target TargetSelector) ([]string, error) {
+	obj, err := ParseExperiment(experimentYAML)
+	if err != nil {
+		return nil, err
+	}
+	target.Pods = nil
+	injection := &selectorInjection{target: target}
+	if err := c.inject(obj, injection); err != nil {
+		return nil, fmt.Errorf("failed to inject selector: %w", err)
+	}
+
+	namespaces := make([]string, 0, len(injection.namespaces))
+	for namespace := range injection.namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// ListTargetPods lists the names, sorted, of the pods in namespace matching labels that can
+// still be targeted. Pods being deleted or that already terminated are skipped, Chaos Mesh
+// would not inject them either.
+func (c *Client) ListTargetPods(ctx context.Context, namespace string, labels map[string]string) ([]string, error) {
+	list, err := c.dynamicClient.Resource(podGVR).Namespace(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: k8slabels.SelectorFromSet(labels).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+	}
+
+	var names []string
+	for _, pod := range list.Items {
+		if pod.GetDeletionTimestamp() != nil {
+			continue
+		}
+		phase, _, _ := unstructured.NestedString(pod.Object, "status", "phase")
+		if phase == "Succeeded" || phase == "Failed" {
+			continue
+		}
+		names = append(names, pod.GetName())
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,126 @@
+package chaos
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newPod(name, namespace, hash, phase string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+			"labels":    map[string]interface{}{"app": "payments", "rollouts-pod-template-hash": hash},
+		},
+		"status": map[string]interface{}{"phase": phase},
+	}}
+}
+
+func TestListTargetPods(t *testing.T) {
+	client := newFakeClient(t)
+	terminating := newPod("payments-canary-terminating", "default", "canary", "Running")
+	now := metav1.Now()
+	terminating.SetDeletionTimestamp(&now)
+	for _, pod := range []*unstructured.Unstructured{
+		newPod("payments-canary-b", "default", "canary", "Running"),
+		newPod("payments-canary-a", "default", "canary", "Pending"),
+		newPod("payments-canary-done", "default", "canary", "Succeeded"),
+		newPod("payments-stable-a", "default", "stable", "Running"),
+		newPod("payments-canary-other", "team-a", "canary", "Running"),
+		terminating,
+	} {
+		if _, err := client.dynamicClient.Resource(podGVR).Namespace(pod.GetNamespace()).Create(context.Background(), pod, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Failed to create pod: %v", err)
+		}
+	}
+
+	pods, err := client.ListTargetPods(context.Background(), "default", map[string]string{"rollouts-pod-template-hash": "canary"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"payments-canary-a", "payments-canary-b"}
+	if !reflect.DeepEqual(pods, expected) {
+		t.Errorf("Expected pods %v, got %v", expected, pods)
+	}
+}
+
+func TestTargetNamespaces(t *testing.T) {
+	client := newFakeClient(t)
+	target := TargetSelector{Labels: map[string]string{"track": "canary"}, Namespace: "team-a", NetworkSide: NetworkSideTarget}
+
+	tests := []struct {
+		name     string
+		yaml     string
+		expected []string
+	}{
+		{
+			name: "Template without namespaces",
+			yaml: `apiVersion: chaos-mesh.org/v1alpha1
+kind: PodChaos
+metadata:
+  name: pod-kill
+spec:
+  action: pod-kill
+  mode: one`,
+			expected: []string{"team-a"},
+		},
+		{
+			name: "Template namespaces",
+			yaml: `apiVersion: chaos-mesh.org/v1alpha1
+kind: PodChaos
+metadata:
+  name: pod-kill
+spec:
+  action: pod-kill
+  mode: one
+  selector:
+    namespaces: [team-c, team-b]`,
+			expected: []string{"team-b", "team-c"},
+		},
+		{
+			name: "Only the NetworkChaos side that gets the labels",
+			yaml: `apiVersion: chaos-mesh.org/v1alpha1
+kind: NetworkChaos
+metadata:
+  name: delay
+spec:
+  action: delay
+  mode: all
+  selector:
+    namespaces: [team-b]
+  target:
+    mode: all
+    selector:
+      namespaces: [team-c]`,
+			expected: []string{"team-c"},
+		},
+		{
+			name: "Cloud chaos selects no pods",
+			yaml: `apiVersion: chaos-mesh.org/v1alpha1
+kind: AWSChaos
+metadata:
+  name: ec2-stop
+spec:
+  action: ec2-stop`,
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespaces, err := client.TargetNamespaces(tt.yaml, target)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(namespaces, tt.expected) {
+				t.Errorf("Expected namespaces %v, got %v", tt.expected, namespaces)
+			}
+		})
+	}
+}
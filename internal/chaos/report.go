@@ -0,0 +1,101 @@
+package chaos
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TargetReport describes how an experiment affected one of its targets
+type TargetReport struct {
+	// Target identifies the pod or container, e.g. default/web-0/nginx
+	Target string `json:"target"`
+	// InjectedAt is when the fault was first injected into the target
+	InjectedAt *time.Time `json:"injectedAt,omitempty"`
+	// RecoveredAt is when the target was last recovered
+	RecoveredAt *time.Time `json:"recoveredAt,omitempty"`
+	// Injected reports whether the fault was injected into the target at least once
+	Injected bool `json:"injected"`
+	// Recovered reports whether an injected target was recovered again
+	Recovered bool `json:"recovered"`
+}
+
+// NotRecovered reports whether the fault was injected into the target and never recovered
+func (t TargetReport) NotRecovered() bool {
+	return t.Injected && !t.Recovered
+}
+
+// ExperimentTargets reports the targets of an experiment from the container records of
+// Chaos Mesh 2.x, or the pod records of Chaos Mesh 1.x
+func ExperimentTargets(obj *unstructured.Unstructured) []TargetReport {
+	records, found, _ := unstructured.NestedSlice(obj.Object, "status", "experiment", "containerRecords")
+	if !found {
+		return legacyTargets(obj)
+	}
+
+	targets := make([]TargetReport, 0, len(records))
+	for _, item := range records {
+		record, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		target := TargetReport{Injected: recordInjected(record)}
+		target.Target, _, _ = unstructured.NestedString(record, "id")
+		phase, _, _ := unstructured.NestedString(record, "phase")
+		target.Recovered = target.Injected && phase == "NotInjected"
+
+		var injectedAt, recoveredAt time.Time
+		events, _, _ := unstructured.NestedSlice(record, "events")
+		for _, eventItem := range events {
+			event, ok := eventItem.(map[string]interface{})
+			if !ok || event["type"] != "Succeeded" {
+				continue
+			}
+			timestamp := nestedTime(event, "timestamp")
+			if timestamp.IsZero() {
+				continue
+			}
+			switch event["operation"] {
+			case "Apply":
+				injectedAt = earliest(injectedAt, timestamp)
+			case "Recover":
+				recoveredAt = latest(recoveredAt, timestamp)
+			}
+		}
+		if !injectedAt.IsZero() {
+			target.InjectedAt = &injectedAt
+		}
+		if target.Recovered && !recoveredAt.IsZero() {
+			target.RecoveredAt = &recoveredAt
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// legacyTargets reports the pods recorded on a Chaos Mesh 1.x experiment, which share the
+// start and end time of the experiment
+func legacyTargets(obj *unstructured.Unstructured) []TargetReport {
+	records, _, _ := unstructured.NestedSlice(obj.Object, "status", "experiment", "podRecords")
+	start := nestedTime(obj.Object, "status", "experiment", "startTime")
+	end := nestedTime(obj.Object, "status", "experiment", "endTime")
+
+	targets := make([]TargetReport, 0, len(records))
+	for _, item := range records {
+		record, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		namespace, _, _ := unstructured.NestedString(record, "namespace")
+		name, _, _ := unstructured.NestedString(record, "name")
+		target := TargetReport{Target: namespace + "/" + name, Injected: true, Recovered: !end.IsZero()}
+		if !start.IsZero() {
+			target.InjectedAt = &start
+		}
+		if !end.IsZero() {
+			target.RecoveredAt = &end
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
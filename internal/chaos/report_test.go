@@ -0,0 +1,87 @@
+package chaos
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestExperimentTargets(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"experiment": map[string]interface{}{
+				"containerRecords": []interface{}{
+					map[string]interface{}{
+						"id":    "default/web-0/nginx",
+						"phase": "NotInjected",
+						"events": []interface{}{
+							map[string]interface{}{"operation": "Apply", "type": "Succeeded", "timestamp": "2024-01-01T10:00:05Z"},
+							map[string]interface{}{"operation": "Recover", "type": "Succeeded", "timestamp": "2024-01-01T10:01:05Z"},
+						},
+					},
+					map[string]interface{}{
+						"id":    "default/web-1/nginx",
+						"phase": "Injected",
+						"events": []interface{}{
+							map[string]interface{}{"operation": "Apply", "type": "Succeeded", "timestamp": "2024-01-01T10:00:07Z"},
+							map[string]interface{}{"operation": "Recover", "type": "Failed", "timestamp": "2024-01-01T10:01:05Z"},
+						},
+					},
+					map[string]interface{}{
+						"id":    "default/web-2/nginx",
+						"phase": "NotInjected",
+						"events": []interface{}{
+							map[string]interface{}{"operation": "Apply", "type": "Failed", "timestamp": "2024-01-01T10:00:07Z"},
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	targets := ExperimentTargets(obj)
+	if len(targets) != 3 {
+		t.Fatalf("Expected 3 targets, got %d", len(targets))
+	}
+
+	recovered := targets[0]
+	if !recovered.Injected || !recovered.Recovered || recovered.NotRecovered() {
+		t.Errorf("Expected web-0 to be injected and recovered, got %+v", recovered)
+	}
+	if recovered.InjectedAt == nil || !recovered.InjectedAt.Equal(time.Date(2024, 1, 1, 10, 0, 5, 0, time.UTC)) {
+		t.Errorf("Expected web-0 to be injected at 10:00:05, got %v", recovered.InjectedAt)
+	}
+	if recovered.RecoveredAt == nil || !recovered.RecoveredAt.Equal(time.Date(2024, 1, 1, 10, 1, 5, 0, time.UTC)) {
+		t.Errorf("Expected web-0 to be recovered at 10:01:05, got %v", recovered.RecoveredAt)
+	}
+
+	if !targets[1].NotRecovered() || targets[1].RecoveredAt != nil {
+		t.Errorf("Expected web-1 to be reported as not recovered, got %+v", targets[1])
+	}
+	if targets[2].Injected || targets[2].InjectedAt != nil || targets[2].NotRecovered() {
+		t.Errorf("Expected web-2 to be reported as never injected, got %+v", targets[2])
+	}
+}
+
+func TestExperimentTargetsLegacy(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"experiment": map[string]interface{}{
+				"phase":     "Running",
+				"startTime": "2024-01-01T10:00:00Z",
+				"podRecords": []interface{}{
+					map[string]interface{}{"name": "web-0", "namespace": "default"},
+				},
+			},
+		},
+	}}
+
+	targets := ExperimentTargets(obj)
+	if len(targets) != 1 || targets[0].Target != "default/web-0" {
+		t.Fatalf("Expected target default/web-0, got %+v", targets)
+	}
+	if !targets[0].NotRecovered() || targets[0].InjectedAt == nil {
+		t.Errorf("Expected a running experiment to report an injected, not yet recovered pod, got %+v", targets[0])
+	}
+}
@@ -0,0 +1,160 @@
+package chaos
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+)
+
+// ChaosGroup is the API group of Chaos Mesh resources
+const ChaosGroup = "chaos-mesh.org"
+
+// chaosResources maps the Chaos Mesh kinds of chaos-mesh.org/v1alpha1 to their resource names.
+// Clients without discovery, such as those created by NewClientWithDynamic, resolve kinds
+// from this table.
+var chaosResources = map[string]string{
+	"PodChaos":             "podchaos",
+	"NetworkChaos":         "networkchaos",
+	"StressChaos":          "stresschaos",
+	"IOChaos":              "iochaos",
+	"TimeChaos":            "timechaos",
+	"KernelChaos":          "kernelchaos",
+	"DNSChaos":             "dnschaos",
+	"HTTPChaos":            "httpchaos",
+	"JVMChaos":             "jvmchaos",
+	"BlockChaos":           "blockchaos",
+	"PhysicalMachineChaos": "physicalmachinechaos",
+	"AWSChaos":             "awschaos",
+	"GCPChaos":             "gcpchaos",
+	"AzureChaos":           "azurechaos",
+	KindWorkflow:           "workflows",
+	KindWorkflowNode:       "workflownodes",
+	KindSchedule:           "schedules",
+}
+
+// staticMapper resolves the kinds of chaosResources
+var staticMapper = func() meta.RESTMapper {
+	groupVersion := schema.GroupVersion{Group: ChaosGroup, Version: "v1alpha1"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{groupVersion})
+	for kind, resource := range chaosResources {
+		mapper.AddSpecific(groupVersion.WithKind(kind), groupVersion.WithResource(resource), groupVersion.WithResource(strings.ToLower(kind)), meta.RESTScopeNamespace)
+	}
+	return mapper
+}()
+
+// isChaosKind reports whether kind is a chaos experiment rather than another Chaos Mesh
+// resource such as a Schedule or a Workflow
+func isChaosKind(kind string) bool {
+	return strings.HasSuffix(kind, "Chaos")
+}
+
+// isManagedKind reports whether the plugin creates objects of kind: chaos experiments,
+// workflows and schedules, but not the nodes Chaos Mesh creates for a workflow
+func isManagedKind(kind string) bool {
+	return isChaosKind(kind) || kind == KindWorkflow || kind == KindSchedule
+}
+
+// restMapper returns the mapper used to resolve kinds to resources
+func (c *Client) restMapper() meta.RESTMapper {
+	if c.mapper == nil {
+		return staticMapper
+	}
+	return c.mapper
+}
+
+// getGVR resolves a Chaos Mesh kind to its resource. An empty apiVersion, or just the group
+// name, resolves to the version preferred by the API server. Discovery data is refreshed
+// once before giving up, so CRDs installed after the plugin started are picked up.
+func (c *Client) getGVR(apiVersion, kind string) (schema.GroupVersionResource, error) {
+	groupVersion := schema.GroupVersion{Group: ChaosGroup}
+	if strings.Contains(apiVersion, "/") {
+		parsed, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			return schema.GroupVersionResource{}, fmt.Errorf("invalid apiVersion %s: %w", apiVersion, err)
+		}
+		groupVersion = parsed
+	} else if apiVersion != "" {
+		groupVersion.Group = apiVersion
+	}
+	if groupVersion.Group != ChaosGroup {
+		return schema.GroupVersionResource{}, fmt.Errorf("unsupported API group %s: only %s resources are supported", groupVersion.Group, ChaosGroup)
+	}
+
+	var versions []string
+	if groupVersion.Version != "" {
+		versions = append(versions, groupVersion.Version)
+	}
+	groupKind := schema.GroupKind{Group: ChaosGroup, Kind: kind}
+
+	mapping, err := c.restMapper().RESTMapping(groupKind, versions...)
+	if meta.IsNoMatchError(err) {
+		if resettable, ok := c.restMapper().(meta.ResettableRESTMapper); ok {
+			resettable.Reset()
+			mapping, err = c.restMapper().RESTMapping(groupKind, versions...)
+		}
+	}
+	if meta.IsNoMatchError(err) {
+		requested := strings.TrimSuffix(groupVersion.String(), "/")
+		installed, listErr := c.chaosKinds()
+		if listErr != nil {
+			return schema.GroupVersionResource{}, fmt.Errorf("unsupported chaos kind %s in %s (failed to list installed chaos kinds: %v)", kind, requested, listErr)
+		}
+		return schema.GroupVersionResource{}, fmt.Errorf("unsupported chaos kind %s in %s, installed chaos kinds: %s", kind, requested, strings.Join(installed, ", "))
+	}
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("failed to resolve chaos kind %s: %w", kind, err)
+	}
+	return mapping.Resource, nil
+}
+
+// chaosKinds returns the installed kinds the plugin can create, sorted by name
+func (c *Client) chaosKinds() ([]string, error) {
+	var kinds []string
+	if c.discovery == nil {
+		for kind := range chaosResources {
+			if isManagedKind(kind) {
+				kinds = append(kinds, kind)
+			}
+		}
+		sort.Strings(kinds)
+		return kinds, nil
+	}
+
+	groups, err := c.discovery.ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, group := range groups.Groups {
+		if group.Name != ChaosGroup {
+			continue
+		}
+		resources, err := c.discovery.ServerResourcesForGroupVersion(group.PreferredVersion.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range resources.APIResources {
+			// Skip subresources such as podchaos/status
+			if strings.Contains(resource.Name, "/") || !isManagedKind(resource.Kind) || seen[resource.Kind] {
+				continue
+			}
+			seen[resource.Kind] = true
+			kinds = append(kinds, resource.Kind)
+		}
+	}
+	sort.Strings(kinds)
+	return kinds, nil
+}
+
+// newDiscoveryMapper returns a mapper backed by cached discovery data, along with the cached
+// discovery client it uses
+func newDiscoveryMapper(client discovery.DiscoveryInterface) (meta.ResettableRESTMapper, discovery.CachedDiscoveryInterface) {
+	cached := memory.NewMemCacheClient(client)
+	return restmapper.NewDeferredDiscoveryRESTMapper(cached), cached
+}
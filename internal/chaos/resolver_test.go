@@ -0,0 +1,68 @@
+package chaos
+
+import (
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newDiscoveryClient returns a Client resolving kinds from fake discovery data
+func newDiscoveryClient(resources ...*metav1.APIResourceList) (*Client, *fakediscovery.FakeDiscovery) {
+	discoveryClient := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: resources}}
+	client := NewClientWithDiscovery(fake.NewSimpleDynamicClient(runtime.NewScheme()), discoveryClient, *log.WithFields(log.Fields{"test": "chaos"}))
+	return client, discoveryClient
+}
+
+func TestGetGVRWithDiscovery(t *testing.T) {
+	client, discoveryClient := newDiscoveryClient(
+		&metav1.APIResourceList{
+			GroupVersion: "chaos-mesh.org/v1alpha1",
+			APIResources: []metav1.APIResource{
+				{Name: "podchaos", Kind: "PodChaos", Namespaced: true},
+				{Name: "podchaos/status", Kind: "PodChaos", Namespaced: true},
+				{Name: "blockchaos", Kind: "BlockChaos", Namespaced: true},
+				{Name: "schedules", Kind: "Schedule", Namespaced: true},
+			},
+		},
+		&metav1.APIResourceList{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment", Namespaced: true}},
+		},
+	)
+
+	// Kinds unknown to the plugin are resolved from discovery, with or without a version
+	for _, apiVersion := range []string{"chaos-mesh.org/v1alpha1", "chaos-mesh.org", ""} {
+		gvr, err := client.getGVR(apiVersion, "BlockChaos")
+		if err != nil {
+			t.Fatalf("Unexpected error for apiVersion '%s': %v", apiVersion, err)
+		}
+		expected := schema.GroupVersionResource{Group: "chaos-mesh.org", Version: "v1alpha1", Resource: "blockchaos"}
+		if gvr != expected {
+			t.Errorf("Expected GVR %+v, got %+v", expected, gvr)
+		}
+	}
+
+	// Unknown kinds list what is installed
+	_, err := client.getGVR("chaos-mesh.org/v1alpha1", "NetworkChaos")
+	if err == nil || !strings.Contains(err.Error(), "installed chaos kinds: BlockChaos, PodChaos") {
+		t.Errorf("Expected error listing the installed chaos kinds, got %v", err)
+	}
+
+	// Kinds installed after the cache was filled are found after a refresh
+	discoveryClient.Resources[0].APIResources = append(discoveryClient.Resources[0].APIResources, metav1.APIResource{Name: "networkchaos", Kind: "NetworkChaos", Namespaced: true})
+	if _, err := client.getGVR("chaos-mesh.org/v1alpha1", "NetworkChaos"); err != nil {
+		t.Errorf("Expected newly installed kind to resolve, got %v", err)
+	}
+
+	// Only Chaos Mesh resources are accepted
+	if _, err := client.getGVR("apps/v1", "Deployment"); err == nil {
+		t.Errorf("Expected error for a resource outside %s", ChaosGroup)
+	}
+}
@@ -0,0 +1,240 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// KindSchedule is the kind of Chaos Mesh schedules, which spawn an experiment or workflow
+	// on every tick of a cron expression
+	KindSchedule = "Schedule"
+
+	// LabelScheduledBy is set by Chaos Mesh on every object spawned by a schedule
+	LabelScheduledBy = "managed-by"
+)
+
+// ScheduleRuns summarises the runs a schedule spawned
+type ScheduleRuns struct {
+	// Total is the number of runs spawned so far
+	Total int `json:"total"`
+	// Succeeded is the number of runs that injected and recovered their fault
+	Succeeded int `json:"succeeded"`
+	// Failed is the number of runs that finished without injecting or recovering their fault
+	Failed int `json:"failed"`
+	// Running is the number of runs that have not finished yet
+	Running int `json:"running"`
+}
+
+// Success reports whether the schedule ran at least once and no run failed. Runs still in
+// progress are not judged: the schedule is stopped at the end of the measurement and the
+// last run may have started just before that.
+func (r ScheduleRuns) Success() bool {
+	return r.Succeeded > 0 && r.Failed == 0
+}
+
+// Add combines the runs of another schedule into r
+func (r *ScheduleRuns) Add(other ScheduleRuns) {
+	r.Total += other.Total
+	r.Succeeded += other.Succeeded
+	r.Failed += other.Failed
+	r.Running += other.Running
+}
+
+// String describes the outcome of the runs, e.g. "all 6 runs injected and recovered"
+func (r ScheduleRuns) String() string {
+	switch {
+	case r.Total == 0:
+		return "the schedule spawned no runs"
+	case r.Succeeded == r.Total:
+		return fmt.Sprintf("all %d runs injected and recovered", r.Total)
+	}
+
+	message := fmt.Sprintf("%d of %d runs injected and recovered", r.Succeeded, r.Total)
+	if r.Failed > 0 {
+		message += fmt.Sprintf(", %d failed", r.Failed)
+	}
+	if r.Running > 0 {
+		message += fmt.Sprintf(", %d still running", r.Running)
+	}
+	return message
+}
+
+// Outcomes of a single run spawned by a schedule
+const (
+	RunOutcomeRunning   = "running"
+	RunOutcomeSucceeded = "succeeded"
+	RunOutcomeFailed    = "failed"
+)
+
+// ScheduleRun is the outcome of one experiment or workflow spawned by a schedule
+type ScheduleRun struct {
+	Name    string `json:"name"`
+	Outcome string `json:"outcome"`
+	Stats   Stats  `json:"stats"`
+}
+
+// finished reports whether the run reached its final outcome
+func (r ScheduleRun) finished() bool {
+	return r.Outcome == RunOutcomeSucceeded || r.Outcome == RunOutcomeFailed
+}
+
+// ScheduleRunRecords holds the runs of a schedule by UID, or by name for runs without one
+type ScheduleRunRecords map[string]ScheduleRun
+
+// Summarize aggregates the outcomes and statistics of the runs
+func (r ScheduleRunRecords) Summarize() (ScheduleRuns, Stats) {
+	var runs ScheduleRuns
+	var stats Stats
+	for _, run := range r {
+		runs.Total++
+		switch run.Outcome {
+		case RunOutcomeSucceeded:
+			runs.Succeeded++
+		case RunOutcomeFailed:
+			runs.Failed++
+		default:
+			runs.Running++
+		}
+		stats.Add(run.Stats)
+	}
+	return runs, stats
+}
+
+// ScheduleRunHistory tracks the runs of a schedule across reconciles. Chaos Mesh prunes the
+// runs beyond the historyLimit of the schedule, so the runs listed last are kept one by one
+// and runs pruned since are folded into totals, keeping the history bounded by the
+// historyLimit however long the schedule runs.
+type ScheduleRunHistory struct {
+	// Listed are the runs listed on the last reconcile
+	Listed ScheduleRunRecords `json:"listed,omitempty"`
+	// Pruned sums up the runs that were listed once and no longer are
+	Pruned      ScheduleRuns `json:"pruned"`
+	PrunedStats Stats        `json:"prunedStats"`
+}
+
+// Merge records the runs listed now. Finished runs never change again, and runs missing from
+// listed were pruned for good, so they are only counted from then on.
+func (h ScheduleRunHistory) Merge(listed ScheduleRunRecords) ScheduleRunHistory {
+	merged := ScheduleRunHistory{
+		Listed:      make(ScheduleRunRecords, len(listed)),
+		Pruned:      h.Pruned,
+		PrunedStats: h.PrunedStats,
+	}
+	for key, run := range listed {
+		if seen, exists := h.Listed[key]; exists && seen.finished() {
+			run = seen
+		}
+		merged.Listed[key] = run
+	}
+
+	pruned := make(ScheduleRunRecords)
+	for key, run := range h.Listed {
+		if _, exists := listed[key]; !exists {
+			pruned[key] = run
+		}
+	}
+	runs, stats := pruned.Summarize()
+	merged.Pruned.Add(runs)
+	merged.PrunedStats.Add(stats)
+	return merged
+}
+
+// Summarize aggregates the outcomes and statistics of every run seen so far
+func (h ScheduleRunHistory) Summarize() (ScheduleRuns, Stats) {
+	runs, stats := h.Listed.Summarize()
+	runs.Add(h.Pruned)
+	stats.Add(h.PrunedStats)
+	return runs, stats
+}
+
+// Empty reports whether no run was seen yet
+func (h ScheduleRunHistory) Empty() bool {
+	return len(h.Listed) == 0 && h.Pruned.Total == 0
+}
+
+// ListScheduleRuns lists the experiments or workflows a schedule spawned that still exist,
+// with their current outcome
+func (c *Client) ListScheduleRuns(ctx context.Context, schedule *unstructured.Unstructured) (ScheduleRunRecords, error) {
+	kind, _, _ := unstructured.NestedString(schedule.Object, "spec", "type")
+	if kind == "" {
+		return nil, fmt.Errorf("schedule %s has no type", schedule.GetName())
+	}
+	gvr, err := c.getGVR(schedule.GetAPIVersion(), kind)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := c.dynamicClient.Resource(gvr).Namespace(schedule.GetNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{LabelScheduledBy: schedule.GetName()}).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs of schedule %s: %w", schedule.GetName(), err)
+	}
+
+	records := make(ScheduleRunRecords, len(list.Items))
+	for i := range list.Items {
+		item := &list.Items[i]
+		// Runs of an earlier schedule with the same name are not counted
+		if !ownedBy(item, schedule) {
+			continue
+		}
+		item.SetKind(kind)
+
+		run := ScheduleRun{Name: item.GetName(), Outcome: RunOutcomeRunning, Stats: ExperimentStats(item)}
+		success, finished, err := c.ExperimentStatus(ctx, item)
+		switch {
+		case err != nil:
+			c.logger.Warnf("Failed to get status of schedule run %s: %v", item.GetName(), err)
+		case !finished:
+		case success:
+			run.Outcome = RunOutcomeSucceeded
+		default:
+			run.Outcome = RunOutcomeFailed
+		}
+
+		key := string(item.GetUID())
+		if key == "" {
+			key = item.GetName()
+		}
+		records[key] = run
+	}
+	return records, nil
+}
+
+// injectScheduleSelector injects the target selector into the experiment or workflow a
+// schedule spawns. The spec of a schedule embeds it like a workflow template does.
+func (c *Client) injectScheduleSelector(obj *unstructured.Unstructured, injection *selectorInjection) error {
+	spec, found, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return fmt.Errorf("failed to get spec: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("spec not found in schedule")
+	}
+
+	kind, _, _ := unstructured.NestedString(spec, "type")
+	if err := injection.injectEmbedded(spec, kind, "spec"); err != nil {
+		return fmt.Errorf("schedule %s: %w", obj.GetName(), err)
+	}
+	return unstructured.SetNestedMap(obj.Object, spec, "spec")
+}
+
+// ownedBy reports whether obj is controlled by owner. Objects are kept when owner has no UID
+// yet or obj carries no owner references at all.
+func ownedBy(obj, owner *unstructured.Unstructured) bool {
+	refs := obj.GetOwnerReferences()
+	if owner.GetUID() == "" || len(refs) == 0 {
+		return true
+	}
+	for _, ref := range refs {
+		if ref.UID == owner.GetUID() {
+			return true
+		}
+	}
+	return false
+}
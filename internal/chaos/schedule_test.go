@@ -0,0 +1,180 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newSchedule() *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "chaos-mesh.org/v1alpha1",
+			"kind":       "Schedule",
+			"metadata": map[string]interface{}{
+				"name":      "recurring-kill",
+				"namespace": "default",
+				"uid":       "schedule-uid",
+			},
+			"spec": map[string]interface{}{
+				"schedule":          "@every 30s",
+				"type":              "PodChaos",
+				"historyLimit":      int64(10),
+				"concurrencyPolicy": "Forbid",
+				"podChaos": map[string]interface{}{
+					"action":   "pod-kill",
+					"mode":     "one",
+					"selector": map[string]interface{}{"namespaces": []interface{}{"default"}},
+				},
+			},
+		},
+	}
+}
+
+func TestInjectScheduleSelector(t *testing.T) {
+	client := &Client{
+		logger: *log.WithFields(log.Fields{"test": "chaos"}),
+	}
+
+	obj := newSchedule()
+	if _, err := client.injectSelector(obj, TargetSelector{Labels: map[string]string{"app": "test-app"}}); err != nil {
+		t.Fatalf("Failed to inject selector: %v", err)
+	}
+	value, _, _ := unstructured.NestedString(obj.Object, "spec", "podChaos", "selector", "labelSelectors", "app")
+	if value != "test-app" {
+		t.Errorf("Expected selector to be injected into the scheduled chaos, got '%s'", value)
+	}
+
+	// Schedules may spawn workflows as well
+	workflow := newWorkflow()
+	obj = newSchedule()
+	unstructured.RemoveNestedField(obj.Object, "spec", "podChaos")
+	_ = unstructured.SetNestedField(obj.Object, "Workflow", "spec", "type")
+	_ = unstructured.SetNestedMap(obj.Object, workflow.Object["spec"].(map[string]interface{}), "spec", "workflow")
+	if _, err := client.injectSelector(obj, TargetSelector{Labels: map[string]string{"app": "test-app"}}); err != nil {
+		t.Fatalf("Failed to inject selector: %v", err)
+	}
+	templates, _, _ := unstructured.NestedSlice(obj.Object, "spec", "workflow", "templates")
+	value, _, _ = unstructured.NestedString(templates[1].(map[string]interface{}), "podChaos", "selector", "labelSelectors", "app")
+	if value != "test-app" {
+		t.Errorf("Expected selector to be injected into the scheduled workflow, got '%s'", value)
+	}
+
+	// A schedule without its embedded chaos is rejected
+	obj = newSchedule()
+	unstructured.RemoveNestedField(obj.Object, "spec", "podChaos")
+	if _, err := client.injectSelector(obj, TargetSelector{Labels: map[string]string{"app": "test-app"}}); err == nil {
+		t.Errorf("Expected an error for a schedule without podChaos")
+	}
+}
+
+func TestScheduleRuns(t *testing.T) {
+	newRun := func(name, schedule, ownerUID, phase string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "chaos-mesh.org/v1alpha1",
+				"kind":       "PodChaos",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": "default",
+					"labels":    map[string]interface{}{LabelScheduledBy: schedule},
+				},
+			},
+		}
+		if ownerUID != "" {
+			obj.SetOwnerReferences([]metav1.OwnerReference{{Kind: KindSchedule, Name: schedule, UID: types.UID(ownerUID)}})
+		}
+		if phase != "" {
+			_ = unstructured.SetNestedField(obj.Object, phase, "status", "experiment", "phase")
+		}
+		return obj
+	}
+
+	client := newFakeClient(t,
+		newRun("run-1", "recurring-kill", "", "Finished"),
+		newRun("run-2", "recurring-kill", "", "Finished"),
+		newRun("run-3", "recurring-kill", "", "Failed"),
+		newRun("run-4", "recurring-kill", "", "Running"),
+		newRun("stale", "recurring-kill", "other-uid", "Failed"),
+		newRun("other", "other-schedule", "", "Failed"),
+	)
+
+	records, err := client.ListScheduleRuns(context.Background(), newSchedule())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	runs, _ := records.Summarize()
+	expected := ScheduleRuns{Total: 4, Succeeded: 2, Failed: 1, Running: 1}
+	if runs != expected {
+		t.Errorf("Expected runs %+v, got %+v", expected, runs)
+	}
+	if runs.Success() {
+		t.Errorf("Expected runs with a failure not to succeed")
+	}
+	if message := runs.String(); message != "2 of 4 runs injected and recovered, 1 failed, 1 still running" {
+		t.Errorf("Unexpected message '%s'", message)
+	}
+}
+
+func TestScheduleRunHistoryMerge(t *testing.T) {
+	seen := ScheduleRunHistory{
+		Listed: ScheduleRunRecords{
+			"uid-1": {Name: "run-1", Outcome: RunOutcomeFailed, Stats: Stats{TargetCount: 1, InjectedCount: 1}},
+			"uid-2": {Name: "run-2", Outcome: RunOutcomeRunning},
+			"uid-3": {Name: "run-3", Outcome: RunOutcomeSucceeded},
+		},
+		Pruned:      ScheduleRuns{Total: 2, Succeeded: 2},
+		PrunedStats: Stats{TargetCount: 2, InjectedCount: 2, RecoveredCount: 2},
+	}
+	// run-1 was pruned by the historyLimit, run-2 finished and run-4 started
+	listed := ScheduleRunRecords{
+		"uid-2": {Name: "run-2", Outcome: RunOutcomeSucceeded},
+		"uid-3": {Name: "run-3", Outcome: RunOutcomeRunning},
+		"uid-4": {Name: "run-4", Outcome: RunOutcomeRunning},
+	}
+
+	merged := seen.Merge(listed)
+	runs, stats := merged.Summarize()
+	expected := ScheduleRuns{Total: 6, Succeeded: 4, Failed: 1, Running: 1}
+	if runs != expected {
+		t.Errorf("Expected runs %+v, got %+v", expected, runs)
+	}
+	if stats.TargetCount != 3 || stats.InjectedCount != 3 {
+		t.Errorf("Expected the stats of the pruned run to be kept, got %+v", stats)
+	}
+	if len(merged.Listed) != 3 || merged.Pruned != (ScheduleRuns{Total: 3, Succeeded: 2, Failed: 1}) {
+		t.Errorf("Expected only the listed runs to be kept one by one, got %+v", merged)
+	}
+	if merged.Listed["uid-3"].Outcome != RunOutcomeSucceeded {
+		t.Errorf("Expected a finished run to keep its outcome, got %+v", merged.Listed["uid-3"])
+	}
+	if len(seen.Listed) != 3 || seen.Pruned.Total != 2 {
+		t.Errorf("Expected Merge to leave the seen runs untouched, got %+v", seen)
+	}
+}
+
+func TestScheduleRunsString(t *testing.T) {
+	tests := []struct {
+		runs     ScheduleRuns
+		success  bool
+		expected string
+	}{
+		{runs: ScheduleRuns{}, success: false, expected: "the schedule spawned no runs"},
+		{runs: ScheduleRuns{Total: 6, Succeeded: 6}, success: true, expected: "all 6 runs injected and recovered"},
+		{runs: ScheduleRuns{Total: 6, Succeeded: 5, Running: 1}, success: true, expected: "5 of 6 runs injected and recovered, 1 still running"},
+		{runs: ScheduleRuns{Total: 1, Running: 1}, success: false, expected: "0 of 1 runs injected and recovered, 1 still running"},
+	}
+
+	for _, tt := range tests {
+		if message := tt.runs.String(); message != tt.expected {
+			t.Errorf("Expected '%s', got '%s'", tt.expected, message)
+		}
+		if tt.runs.Success() != tt.success {
+			t.Errorf("Expected success %v for %+v, got %v", tt.success, tt.runs, tt.runs.Success())
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package chaos
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Merge strategies decide how the target selector combines with the labelSelectors the
+// experiment template already sets
+const (
+	// MergeStrategyMerge keeps the template labels and adds the target labels, which win
+	// when both set the same label
+	MergeStrategyMerge = "merge"
+	// MergeStrategyReplace replaces the template labels with the target labels
+	MergeStrategyReplace = "replace"
+	// MergeStrategyFailOnConflict keeps the template labels and adds the target labels, but
+	// refuses to create the experiment when both set the same label to different values
+	MergeStrategyFailOnConflict = "failOnConflict"
+)
+
+// Network sides decide which pod selectors of a NetworkChaos the target selector is injected
+// into. The source pods are selected by spec.selector, the pods on the other side of the
+// network fault by spec.target.selector.
+const (
+	// NetworkSideSource injects the target selector into spec.selector
+	NetworkSideSource = "source"
+	// NetworkSideTarget injects the target selector into spec.target.selector
+	NetworkSideTarget = "target"
+	// NetworkSideBoth injects the target selector into both selectors
+	NetworkSideBoth = "both"
+)
+
+// kindNetworkChaos is the only chaos kind selecting pods on both sides of the fault
+const kindNetworkChaos = "NetworkChaos"
+
+// TargetSelector selects the pods of the target ReplicaSet in the experiments created
+type TargetSelector struct {
+	// Labels are injected into the labelSelectors of every pod selector of the experiment
+	Labels map[string]string
+	// MergeStrategy decides how Labels combine with the template labels (default: merge)
+	MergeStrategy string
+	// Namespace is set as the namespaces of pod selectors that select none, so templates do
+	// not depend on the namespace Chaos Mesh falls back to
+	Namespace string
+	// NetworkSide decides which selectors of a NetworkChaos Labels are injected into
+	// (default: source)
+	NetworkSide string
+	// Pods, when set, pins the selectors that get Labels to these pods, keyed by namespace,
+	// through the pods field, which Chaos Mesh evaluates instead of the labels. Each selector
+	// is pinned to the pods of its own namespaces, see TargetNamespaces.
+	Pods map[string][]string
+}
+
+// SelectorOverride is a template label the target selector changed or dropped
+type SelectorOverride struct {
+	// Selector locates the selector in the experiment, e.g. spec.selector
+	Selector string `json:"selector"`
+	Label    string `json:"label"`
+	// Template is the value set by the experiment template
+	Template string `json:"template"`
+	// Injected is the value of the target selector, empty when the label was dropped
+	Injected string `json:"injected,omitempty"`
+}
+
+// String describes the override, e.g. "spec.selector: app=payments overridden by app=checkout"
+func (o SelectorOverride) String() string {
+	if o.Injected == "" {
+		return fmt.Sprintf("%s: %s=%s dropped", o.Selector, o.Label, o.Template)
+	}
+	return fmt.Sprintf("%s: %s=%s overridden by %s=%s", o.Selector, o.Label, o.Template, o.Label, o.Injected)
+}
+
+// selectorInjection injects a target selector into the pod selectors of an experiment and
+// records the template labels it overrides
+type selectorInjection struct {
+	target    TargetSelector
+	overrides []SelectorOverride
+	// namespaces are those of the selectors that got the target labels
+	namespaces map[string]bool
+}
+
+// injectChaos injects the target selector into the pod selectors of the chaos spec of kind
+// found at path. The selector of a NetworkChaos target gets the labels when NetworkSide asks
+// for it, and the namespaces either way.
+func (in *selectorInjection) injectChaos(spec map[string]interface{}, kind string, path string) error {
+	side := in.target.NetworkSide
+	if kind != kindNetworkChaos {
+		side = NetworkSideSource
+	}
+	if err := in.setSelector(spec, path, side != NetworkSideTarget); err != nil {
+		return err
+	}
+	if kind != kindNetworkChaos {
+		return nil
+	}
+
+	target, found, err := unstructured.NestedMap(spec, "target")
+	if err != nil {
+		return fmt.Errorf("failed to get target: %w", err)
+	}
+	injectTarget := side == NetworkSideTarget || side == NetworkSideBoth
+	if !found {
+		if injectTarget {
+			// The mode of the target pods is up to the template author
+			return fmt.Errorf("%s has no target to inject the target selector into, define target.mode and target.selector", path)
+		}
+		return nil
+	}
+	if err := in.setSelector(target, path+".target", injectTarget); err != nil {
+		return err
+	}
+	spec["target"] = target
+	return nil
+}
+
+// setSelector sets the namespaces of the selector in a chaos spec found at path if the
+// template selects none and, with withLabels, merges the target labels into its labelSelectors
+// and pins its pods
+func (in *selectorInjection) setSelector(spec map[string]interface{}, path string, withLabels bool) error {
+	// Get existing selector or create new one
+	selector, found, err := unstructured.NestedMap(spec, "selector")
+	if err != nil {
+		return fmt.Errorf("failed to get selector: %w", err)
+	}
+	if !found {
+		selector = make(map[string]interface{})
+	}
+
+	if withLabels && len(in.target.Labels) > 0 {
+		existing, _, err := unstructured.NestedStringMap(selector, "labelSelectors")
+		if err != nil {
+			return fmt.Errorf("failed to get labelSelectors: %w", err)
+		}
+		labelSelectors, err := in.merge(existing, path+".selector")
+		if err != nil {
+			return err
+		}
+		selector["labelSelectors"] = labelSelectors
+	}
+
+	namespaces, _, _ := unstructured.NestedStringSlice(selector, "namespaces")
+	if len(namespaces) == 0 && in.target.Namespace != "" {
+		namespaces = []string{in.target.Namespace}
+		selector["namespaces"] = []interface{}{in.target.Namespace}
+	}
+	if withLabels {
+		if in.namespaces == nil {
+			in.namespaces = make(map[string]bool)
+		}
+		for _, namespace := range namespaces {
+			in.namespaces[namespace] = true
+		}
+	}
+
+	if withLabels && len(in.target.Pods) > 0 {
+		if len(namespaces) == 0 {
+			return fmt.Errorf("cannot pin the pods of %s without a target namespace", path+".selector")
+		}
+		pinned := make(map[string]interface{}, len(namespaces))
+		for _, namespace := range namespaces {
+			if pods := in.target.Pods[namespace]; len(pods) > 0 {
+				names := make([]interface{}, len(pods))
+				for i, name := range pods {
+					names[i] = name
+				}
+				pinned[namespace] = names
+			}
+		}
+		if len(pinned) == 0 {
+			return fmt.Errorf("no pinned pods in the namespaces %v of %s", namespaces, path+".selector")
+		}
+		selector["pods"] = pinned
+	}
+
+	// Set the updated selector back
+	spec["selector"] = selector
+
+	return nil
+}
+
+// merge combines the template labels of the selector at path with the target labels
+// according to the merge strategy
+func (in *selectorInjection) merge(template map[string]string, path string) (map[string]interface{}, error) {
+	keys := make([]string, 0, len(template))
+	for key := range template {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	merged := make(map[string]interface{}, len(template)+len(in.target.Labels))
+	for _, key := range keys {
+		value := template[key]
+		injected, conflict := in.target.Labels[key]
+		switch {
+		case conflict && injected == value:
+			continue
+		case conflict && in.target.MergeStrategy == MergeStrategyFailOnConflict:
+			return nil, fmt.Errorf("%s sets label %s=%s, which conflicts with the target selector %s=%s", path, key, value, key, injected)
+		case conflict:
+			in.overrides = append(in.overrides, SelectorOverride{Selector: path, Label: key, Template: value, Injected: injected})
+		case in.target.MergeStrategy == MergeStrategyReplace:
+			in.overrides = append(in.overrides, SelectorOverride{Selector: path, Label: key, Template: value})
+		default:
+			merged[key] = value
+		}
+	}
+	for key, value := range in.target.Labels {
+		merged[key] = value
+	}
+	return merged, nil
+}
@@ -0,0 +1,328 @@
+package chaos
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newLabeledExperiment(labelSelectors map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "chaos-mesh.org/v1alpha1",
+		"kind":       "PodChaos",
+		"metadata":   map[string]interface{}{"name": "test-chaos", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"action": "pod-failure",
+			"mode":   "all",
+			"selector": map[string]interface{}{
+				"labelSelectors": labelSelectors,
+			},
+		},
+	}}
+}
+
+func TestInjectSelectorMergeStrategies(t *testing.T) {
+	template := map[string]interface{}{"app": "payments", "tier": "api", "track": "stable"}
+	target := map[string]string{"track": "canary", "tier": "api"}
+
+	tests := []struct {
+		name              string
+		strategy          string
+		expectedLabels    map[string]string
+		expectedOverrides []string
+		expectedErr       string
+	}{
+		{
+			name:              "Merge is the default",
+			strategy:          "",
+			expectedLabels:    map[string]string{"app": "payments", "tier": "api", "track": "canary"},
+			expectedOverrides: []string{"spec.selector: track=stable overridden by track=canary"},
+		},
+		{
+			name:              "Merge",
+			strategy:          MergeStrategyMerge,
+			expectedLabels:    map[string]string{"app": "payments", "tier": "api", "track": "canary"},
+			expectedOverrides: []string{"spec.selector: track=stable overridden by track=canary"},
+		},
+		{
+			name:           "Replace",
+			strategy:       MergeStrategyReplace,
+			expectedLabels: map[string]string{"tier": "api", "track": "canary"},
+			expectedOverrides: []string{
+				"spec.selector: app=payments dropped",
+				"spec.selector: track=stable overridden by track=canary",
+			},
+		},
+		{
+			name:        "Fail on conflict",
+			strategy:    MergeStrategyFailOnConflict,
+			expectedErr: "spec.selector sets label track=stable, which conflicts with the target selector track=canary",
+		},
+	}
+
+	client := newFakeClient(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := newLabeledExperiment(copyLabels(template))
+			overrides, err := client.injectSelector(obj, TargetSelector{Labels: target, MergeStrategy: tt.strategy})
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Errorf("Expected error containing '%s', got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			labels, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector", "labelSelectors")
+			if !reflect.DeepEqual(labels, tt.expectedLabels) {
+				t.Errorf("Expected labelSelectors %v, got %v", tt.expectedLabels, labels)
+			}
+			var descriptions []string
+			for _, override := range overrides {
+				descriptions = append(descriptions, override.String())
+			}
+			if !reflect.DeepEqual(descriptions, tt.expectedOverrides) {
+				t.Errorf("Expected overrides %v, got %v", tt.expectedOverrides, descriptions)
+			}
+		})
+	}
+}
+
+func TestInjectSelectorWorkflowOverrides(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "chaos-mesh.org/v1alpha1",
+		"kind":       "Workflow",
+		"metadata":   map[string]interface{}{"name": "test-workflow", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"entry": "entry",
+			"templates": []interface{}{
+				map[string]interface{}{
+					"name":         "network-delay",
+					"templateType": "NetworkChaos",
+					"networkChaos": map[string]interface{}{
+						"selector": map[string]interface{}{
+							"labelSelectors": map[string]interface{}{"app": "payments"},
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	overrides, err := newFakeClient(t).injectSelector(obj, TargetSelector{Labels: map[string]string{"app": "checkout"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []SelectorOverride{{Selector: "spec.templates[network-delay].networkChaos.selector", Label: "app", Template: "payments", Injected: "checkout"}}
+	if !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("Expected overrides %+v, got %+v", expected, overrides)
+	}
+}
+
+// copyLabels copies a label map so each test case starts from the same template
+func copyLabels(labels map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(labels))
+	for key, value := range labels {
+		copied[key] = value
+	}
+	return copied
+}
+
+func TestInjectSelectorNamespace(t *testing.T) {
+	client := newFakeClient(t)
+	target := TargetSelector{Labels: map[string]string{"app": "test-app"}, Namespace: "team-a"}
+
+	obj := newLabeledExperiment(map[string]interface{}{})
+	if _, err := client.injectSelector(obj, target); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	namespaces, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "selector", "namespaces")
+	if !reflect.DeepEqual(namespaces, []string{"team-a"}) {
+		t.Errorf("Expected the selector to default to namespace team-a, got %v", namespaces)
+	}
+
+	// Namespaces chosen by the template are kept
+	unstructured.SetNestedStringSlice(obj.Object, []string{"payments"}, "spec", "selector", "namespaces")
+	if _, err := client.injectSelector(obj, target); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	namespaces, _, _ = unstructured.NestedStringSlice(obj.Object, "spec", "selector", "namespaces")
+	if !reflect.DeepEqual(namespaces, []string{"payments"}) {
+		t.Errorf("Expected the template namespaces to be kept, got %v", namespaces)
+	}
+}
+
+func newNetworkExperiment(withTarget bool) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"action": "delay",
+		"mode":   "all",
+		"selector": map[string]interface{}{
+			"labelSelectors": map[string]interface{}{"app": "payments"},
+		},
+		"delay": map[string]interface{}{"latency": "100ms"},
+	}
+	if withTarget {
+		spec["direction"] = "to"
+		spec["target"] = map[string]interface{}{
+			"mode": "all",
+			"selector": map[string]interface{}{
+				"labelSelectors": map[string]interface{}{"app": "payments-db"},
+			},
+		}
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "chaos-mesh.org/v1alpha1",
+		"kind":       "NetworkChaos",
+		"metadata":   map[string]interface{}{"name": "test-network", "namespace": "default"},
+		"spec":       spec,
+	}}
+}
+
+func TestInjectSelectorNetworkSides(t *testing.T) {
+	source := map[string]string{"app": "payments"}
+	canarySource := map[string]string{"app": "payments", "track": "canary"}
+	target := map[string]string{"app": "payments-db"}
+	canaryTarget := map[string]string{"app": "payments-db", "track": "canary"}
+
+	tests := []struct {
+		name           string
+		side           string
+		withTarget     bool
+		expectedSource map[string]string
+		expectedTarget map[string]string
+		expectedErr    string
+	}{
+		{
+			name:           "Source is the default",
+			side:           "",
+			withTarget:     true,
+			expectedSource: canarySource,
+			expectedTarget: target,
+		},
+		{
+			name:           "Source",
+			side:           NetworkSideSource,
+			withTarget:     true,
+			expectedSource: canarySource,
+			expectedTarget: target,
+		},
+		{
+			name:           "Target",
+			side:           NetworkSideTarget,
+			withTarget:     true,
+			expectedSource: source,
+			expectedTarget: canaryTarget,
+		},
+		{
+			name:           "Both",
+			side:           NetworkSideBoth,
+			withTarget:     true,
+			expectedSource: canarySource,
+			expectedTarget: canaryTarget,
+		},
+		{
+			name:           "Source without target",
+			side:           NetworkSideSource,
+			expectedSource: canarySource,
+		},
+		{
+			name:        "Target without target",
+			side:        NetworkSideTarget,
+			expectedErr: "spec has no target to inject the target selector into",
+		},
+	}
+
+	client := newFakeClient(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := newNetworkExperiment(tt.withTarget)
+			_, err := client.injectSelector(obj, TargetSelector{Labels: map[string]string{"track": "canary"}, Namespace: "team-a", NetworkSide: tt.side})
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Errorf("Expected error containing '%s', got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			labels, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector", "labelSelectors")
+			if !reflect.DeepEqual(labels, tt.expectedSource) {
+				t.Errorf("Expected source labelSelectors %v, got %v", tt.expectedSource, labels)
+			}
+			labels, found, _ := unstructured.NestedStringMap(obj.Object, "spec", "target", "selector", "labelSelectors")
+			if found != tt.withTarget || !reflect.DeepEqual(labels, tt.expectedTarget) {
+				t.Errorf("Expected target labelSelectors %v, got %v", tt.expectedTarget, labels)
+			}
+			if tt.withTarget {
+				// Both sides are looked up in the target namespace, whichever gets the labels
+				namespaces, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "target", "selector", "namespaces")
+				if !reflect.DeepEqual(namespaces, []string{"team-a"}) {
+					t.Errorf("Expected the target selector to default to namespace team-a, got %v", namespaces)
+				}
+				mode, _, _ := unstructured.NestedString(obj.Object, "spec", "target", "mode")
+				if mode != "all" {
+					t.Errorf("Expected the target mode to be kept, got '%s'", mode)
+				}
+			}
+		})
+	}
+}
+
+func TestInjectSelectorPinnedPods(t *testing.T) {
+	client := newFakeClient(t)
+	target := TargetSelector{
+		Labels:      map[string]string{"track": "canary"},
+		Namespace:   "team-a",
+		NetworkSide: NetworkSideTarget,
+		Pods: map[string][]string{
+			"team-a": {"payments-a", "payments-b"},
+			"team-b": {"payments-c"},
+		},
+	}
+	expected := map[string]interface{}{"team-a": []interface{}{"payments-a", "payments-b"}}
+
+	obj := newLabeledExperiment(map[string]interface{}{"app": "payments"})
+	if _, err := client.injectSelector(obj, target); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	pods, _, _ := unstructured.NestedMap(obj.Object, "spec", "selector", "pods")
+	if !reflect.DeepEqual(pods, expected) {
+		t.Errorf("Expected pinned pods %v, got %v", expected, pods)
+	}
+
+	// Only the NetworkChaos selector that gets the labels is pinned
+	obj = newNetworkExperiment(true)
+	if _, err := client.injectSelector(obj, target); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, found, _ := unstructured.NestedMap(obj.Object, "spec", "selector", "pods"); found {
+		t.Errorf("Expected the source selector not to be pinned")
+	}
+	pods, _, _ = unstructured.NestedMap(obj.Object, "spec", "target", "selector", "pods")
+	if !reflect.DeepEqual(pods, expected) {
+		t.Errorf("Expected pinned target pods %v, got %v", expected, pods)
+	}
+
+	// Selectors naming their namespaces are pinned to the pods of those namespaces
+	obj = newLabeledExperiment(map[string]interface{}{"app": "payments"})
+	unstructured.SetNestedStringSlice(obj.Object, []string{"team-b", "team-c"}, "spec", "selector", "namespaces")
+	if _, err := client.injectSelector(obj, target); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	pods, _, _ = unstructured.NestedMap(obj.Object, "spec", "selector", "pods")
+	if expected := map[string]interface{}{"team-b": []interface{}{"payments-c"}}; !reflect.DeepEqual(pods, expected) {
+		t.Errorf("Expected pinned pods %v, got %v", expected, pods)
+	}
+
+	obj = newLabeledExperiment(map[string]interface{}{"app": "payments"})
+	unstructured.SetNestedStringSlice(obj.Object, []string{"team-c"}, "spec", "selector", "namespaces")
+	if _, err := client.injectSelector(obj, target); err == nil {
+		t.Errorf("Expected an error pinning a selector without pods in its namespaces")
+	}
+}
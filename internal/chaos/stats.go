@@ -0,0 +1,165 @@
+package chaos
+
+import (
+	"math"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Stats summarises how an experiment injected and recovered its fault
+type Stats struct {
+	// TargetCount is the number of targets (containers, pods, ...) selected by the experiment
+	TargetCount int `json:"targetCount"`
+	// InjectedCount is the number of targets the fault was injected into
+	InjectedCount int `json:"injectedCount"`
+	// RecoveredCount is the number of injected targets that were recovered again
+	RecoveredCount int `json:"recoveredCount"`
+	// InjectionSeconds is the time from creating the experiment until the last target was injected
+	InjectionSeconds float64 `json:"injectionSeconds"`
+	// DurationSeconds is the time from the first injection until the last recovery
+	DurationSeconds float64 `json:"durationSeconds"`
+	// RecoverySeconds is how long recovery took: from the end of spec.duration, or from the
+	// first recovery attempt without one, until the last target was recovered
+	RecoverySeconds float64 `json:"recoverySeconds"`
+}
+
+// Add combines the statistics of another experiment into s by summing the counts and
+// keeping the longest durations
+func (s *Stats) Add(other Stats) {
+	s.TargetCount += other.TargetCount
+	s.InjectedCount += other.InjectedCount
+	s.RecoveredCount += other.RecoveredCount
+	s.InjectionSeconds = max(s.InjectionSeconds, other.InjectionSeconds)
+	s.DurationSeconds = max(s.DurationSeconds, other.DurationSeconds)
+	s.RecoverySeconds = max(s.RecoverySeconds, other.RecoverySeconds)
+}
+
+// ExperimentStats reads the injection and recovery statistics of an experiment from its
+// status. Chaos Mesh 2.x container records are used when present, falling back to the pod
+// records and start/end times of Chaos Mesh 1.x.
+func ExperimentStats(obj *unstructured.Unstructured) Stats {
+	records, found, _ := unstructured.NestedSlice(obj.Object, "status", "experiment", "containerRecords")
+	if !found {
+		return legacyStats(obj)
+	}
+
+	var stats Stats
+	var firstApply, lastApply, firstRecover, lastRecover time.Time
+	for _, item := range records {
+		record, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		stats.TargetCount++
+
+		phase, _, _ := unstructured.NestedString(record, "phase")
+		injected := recordInjected(record)
+
+		events, _, _ := unstructured.NestedSlice(record, "events")
+		for _, eventItem := range events {
+			event, ok := eventItem.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			operation, _, _ := unstructured.NestedString(event, "operation")
+			eventType, _, _ := unstructured.NestedString(event, "type")
+			timestamp := nestedTime(event, "timestamp")
+			if timestamp.IsZero() {
+				continue
+			}
+			switch {
+			case operation == "Apply" && eventType == "Succeeded":
+				firstApply = earliest(firstApply, timestamp)
+				lastApply = latest(lastApply, timestamp)
+			case operation == "Recover":
+				firstRecover = earliest(firstRecover, timestamp)
+				if eventType == "Succeeded" {
+					lastRecover = latest(lastRecover, timestamp)
+				}
+			}
+		}
+
+		if injected {
+			stats.InjectedCount++
+			// Recovered targets go back to NotInjected
+			if phase == "NotInjected" {
+				stats.RecoveredCount++
+			}
+		}
+	}
+
+	created := obj.GetCreationTimestamp().Time
+	if !lastApply.IsZero() && !created.IsZero() {
+		stats.InjectionSeconds = seconds(lastApply.Sub(created))
+	}
+	if !lastRecover.IsZero() && !firstApply.IsZero() {
+		stats.DurationSeconds = seconds(lastRecover.Sub(firstApply))
+
+		recoveryStart := firstRecover
+		if duration, err := experimentDuration(obj); err == nil && duration > 0 {
+			recoveryStart = firstApply.Add(duration)
+		}
+		if lastRecover.After(recoveryStart) {
+			stats.RecoverySeconds = seconds(lastRecover.Sub(recoveryStart))
+		}
+	}
+	return stats
+}
+
+// legacyStats reads the statistics Chaos Mesh 1.x records on an experiment
+func legacyStats(obj *unstructured.Unstructured) Stats {
+	var stats Stats
+	records, _, _ := unstructured.NestedSlice(obj.Object, "status", "experiment", "podRecords")
+	stats.TargetCount = len(records)
+	stats.InjectedCount = len(records)
+
+	start := nestedTime(obj.Object, "status", "experiment", "startTime")
+	end := nestedTime(obj.Object, "status", "experiment", "endTime")
+	if !start.IsZero() && !end.IsZero() {
+		stats.RecoveredCount = len(records)
+		stats.DurationSeconds = seconds(end.Sub(start))
+	}
+	return stats
+}
+
+// experimentDuration returns spec.duration of the experiment
+func experimentDuration(obj *unstructured.Unstructured) (time.Duration, error) {
+	value, found, err := unstructured.NestedString(obj.Object, "spec", "duration")
+	if err != nil || !found {
+		return 0, err
+	}
+	return time.ParseDuration(value)
+}
+
+// nestedTime returns the RFC3339 time at the given path, or the zero time
+func nestedTime(obj map[string]interface{}, fields ...string) time.Time {
+	value, found, err := unstructured.NestedString(obj, fields...)
+	if err != nil || !found {
+		return time.Time{}
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
+
+func earliest(current, candidate time.Time) time.Time {
+	if current.IsZero() || candidate.Before(current) {
+		return candidate
+	}
+	return current
+}
+
+func latest(current, candidate time.Time) time.Time {
+	if candidate.After(current) {
+		return candidate
+	}
+	return current
+}
+
+// seconds converts d to seconds rounded to milliseconds
+func seconds(d time.Duration) float64 {
+	return math.Round(d.Seconds()*1000) / 1000
+}
@@ -0,0 +1,74 @@
+package chaos
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestExperimentStats(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	at := func(seconds int) string {
+		return created.Add(time.Duration(seconds) * time.Second).Format(time.RFC3339)
+	}
+	event := func(operation, eventType string, seconds int) interface{} {
+		return map[string]interface{}{"operation": operation, "type": eventType, "timestamp": at(seconds)}
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"duration": "30s"},
+		"status": map[string]interface{}{
+			"experiment": map[string]interface{}{
+				"containerRecords": []interface{}{
+					map[string]interface{}{
+						"id": "default/pod-a", "phase": "NotInjected", "injectedCount": int64(1), "recoveredCount": int64(1),
+						"events": []interface{}{event("Apply", "Succeeded", 2), event("Recover", "Succeeded", 33)},
+					},
+					map[string]interface{}{
+						"id": "default/pod-b", "phase": "Injected", "injectedCount": int64(1),
+						"events": []interface{}{event("Apply", "Succeeded", 4), event("Recover", "Failed", 32)},
+					},
+					map[string]interface{}{
+						"id": "default/pod-c", "phase": "NotInjected",
+						"events": []interface{}{event("Apply", "Failed", 3)},
+					},
+				},
+			},
+		},
+	}}
+	obj.SetCreationTimestamp(metav1.NewTime(created))
+
+	stats := ExperimentStats(obj)
+	expected := Stats{
+		TargetCount:      3,
+		InjectedCount:    2,
+		RecoveredCount:   1,
+		InjectionSeconds: 4,
+		DurationSeconds:  31,
+		RecoverySeconds:  1,
+	}
+	if stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+}
+
+func TestExperimentStatsLegacy(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"experiment": map[string]interface{}{
+				"phase":      "Finished",
+				"startTime":  "2024-01-02T03:04:00Z",
+				"endTime":    "2024-01-02T03:04:45Z",
+				"podRecords": []interface{}{map[string]interface{}{"name": "pod-a"}},
+			},
+		},
+	}}
+
+	stats := ExperimentStats(obj)
+	expected := Stats{TargetCount: 1, InjectedCount: 1, RecoveredCount: 1, DurationSeconds: 45}
+	if stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+}
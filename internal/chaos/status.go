@@ -0,0 +1,191 @@
+package chaos
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Chaos Mesh 2.x experiment conditions
+const (
+	ConditionSelected     = "Selected"
+	ConditionAllInjected  = "AllInjected"
+	ConditionAllRecovered = "AllRecovered"
+	ConditionPaused       = "Paused"
+)
+
+// Desired phases of a Chaos Mesh 2.x experiment
+const (
+	DesiredPhaseRun  = "Run"
+	DesiredPhaseStop = "Stop"
+)
+
+// recordsStatus summarises the container records of a Chaos Mesh 2.x experiment
+type recordsStatus struct {
+	total     int
+	injected  int
+	recovered int
+}
+
+// evaluateRecords decides the status of a Chaos Mesh 2.x experiment, which reports its
+// progress through status.experiment.desiredPhase, per-target container records and the
+// Selected, AllInjected, AllRecovered and Paused conditions instead of a phase.
+//
+// An experiment fails as soon as Chaos Mesh reports why its targets could not be selected;
+// a bare Selected=False only means the targets are not selected yet, see SelectionFailed.
+// Otherwise it finishes
+// once its duration elapsed, or Chaos Mesh stopped it, and every target was recovered;
+// experiments of one-shot actions finish once every target was injected. It succeeds when
+// every selected target was injected.
+func (c *Client) evaluateRecords(obj *unstructured.Unstructured) (success bool, finished bool, err error) {
+	if selectionFailed(obj) {
+		c.logger.Debugf("Experiment %s failed to select its targets", obj.GetName())
+		return false, true, nil
+	}
+	if conditionTrue(obj, ConditionPaused) {
+		return false, false, nil
+	}
+
+	records, err := containerRecords(obj)
+	if err != nil {
+		return false, false, err
+	}
+	if records.total == 0 {
+		// Targets are not selected yet
+		return false, false, nil
+	}
+	allInjected := records.injected == records.total
+
+	action, _, _ := unstructured.NestedString(obj.Object, "spec", "action")
+	duration, _ := experimentDuration(obj)
+	if duration == 0 && allInjected && traitsOf(obj.GetKind()).oneShot(action) {
+		return true, true, nil
+	}
+
+	desiredPhase, _, _ := unstructured.NestedString(obj.Object, "status", "experiment", "desiredPhase")
+	elapsed := duration > 0 && time.Since(obj.GetCreationTimestamp().Time) >= duration
+	if desiredPhase != DesiredPhaseStop && !elapsed {
+		return false, false, nil
+	}
+
+	allRecovered := conditionTrue(obj, ConditionAllRecovered) || records.recovered == records.total
+	if !allRecovered {
+		c.logger.Debugf("Experiment %s is stopping, %d of %d targets recovered", obj.GetName(), records.recovered, records.total)
+		return false, false, nil
+	}
+	return allInjected, true, nil
+}
+
+// selectionFailed reports whether the Selected condition is False with a reason or message,
+// which Chaos Mesh only gives once selecting the targets failed
+func selectionFailed(obj *unstructured.Unstructured) bool {
+	condition, found := findCondition(obj, ConditionSelected)
+	if !found || condition["status"] != "False" {
+		return false
+	}
+	reason, _ := condition["reason"].(string)
+	message, _ := condition["message"].(string)
+	return reason != "" || message != ""
+}
+
+// selectionPending reports whether a Chaos Mesh 2.x experiment has no targets yet while its
+// Selected condition is False without a reason. Chaos Mesh reports the condition that way
+// both before its first selection and after a selection failed, and only tells them apart
+// by the Warning Event it records on the failure.
+func selectionPending(obj *unstructured.Unstructured) bool {
+	condition, found := findCondition(obj, ConditionSelected)
+	if !found || condition["status"] != "False" || selectionFailed(obj) {
+		return false
+	}
+	records, err := containerRecords(obj)
+	return err == nil && records.total == 0
+}
+
+// containerRecords counts the targets of a Chaos Mesh 2.x experiment that were injected and
+// recovered again
+func containerRecords(obj *unstructured.Unstructured) (recordsStatus, error) {
+	var status recordsStatus
+	records, _, err := unstructured.NestedSlice(obj.Object, "status", "experiment", "containerRecords")
+	if err != nil {
+		return status, fmt.Errorf("failed to get container records: %w", err)
+	}
+
+	for _, item := range records {
+		record, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		status.total++
+		phase, _, _ := unstructured.NestedString(record, "phase")
+		if recordInjected(record) {
+			status.injected++
+			// Recovered targets go back to NotInjected
+			if phase == "NotInjected" {
+				status.recovered++
+			}
+		} else if phase == "NotInjected" {
+			// Targets that were never injected have nothing to recover
+			status.recovered++
+		}
+	}
+	return status, nil
+}
+
+// recordInjected reports whether the fault was injected into the target of a container
+// record at least once
+func recordInjected(record map[string]interface{}) bool {
+	phase, _, _ := unstructured.NestedString(record, "phase")
+	injectedCount, _, _ := unstructured.NestedInt64(record, "injectedCount")
+	if injectedCount > 0 || phase == "Injected" {
+		return true
+	}
+
+	events, _, _ := unstructured.NestedSlice(record, "events")
+	for _, item := range events {
+		event, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if event["operation"] == "Apply" && event["type"] == "Succeeded" {
+			return true
+		}
+	}
+	return false
+}
+
+// FailureReason describes why a finished experiment did not succeed, or returns an empty
+// string when its status gives no reason
+func FailureReason(obj *unstructured.Unstructured) string {
+	if condition, found := findCondition(obj, ConditionSelected); found && condition["status"] == "False" {
+		if message, _ := condition["message"].(string); message != "" {
+			return "failed to select targets: " + message
+		}
+		if reason, _ := condition["reason"].(string); reason != "" {
+			return "failed to select targets: " + reason
+		}
+		return "failed to select targets"
+	}
+
+	if records, err := containerRecords(obj); err == nil && records.total > 0 && records.injected < records.total {
+		return fmt.Sprintf("%d of %d targets were never injected", records.total-records.injected, records.total)
+	}
+
+	message, _, _ := unstructured.NestedString(obj.Object, "status", "experiment", "message")
+	return message
+}
+
+// findCondition returns the status condition of the given type
+func findCondition(obj *unstructured.Unstructured, conditionType string) (map[string]interface{}, bool) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == conditionType {
+			return condition, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,213 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// newRecordsExperiment returns a Chaos Mesh 2.x experiment with the given status
+func newRecordsExperiment(action, duration string, created time.Time, desiredPhase string, conditions []interface{}, records ...map[string]interface{}) *unstructured.Unstructured {
+	spec := map[string]interface{}{"action": action}
+	if duration != "" {
+		spec["duration"] = duration
+	}
+	containerRecords := make([]interface{}, len(records))
+	for i, record := range records {
+		containerRecords[i] = record
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "chaos-mesh.org/v1alpha1",
+		"kind":       "PodChaos",
+		"metadata": map[string]interface{}{
+			"name":              "test-chaos",
+			"namespace":         "default",
+			"creationTimestamp": created.UTC().Format(time.RFC3339),
+		},
+		"spec": spec,
+		"status": map[string]interface{}{
+			"conditions": conditions,
+			"experiment": map[string]interface{}{
+				"desiredPhase":     desiredPhase,
+				"containerRecords": containerRecords,
+			},
+		},
+	}}
+}
+
+func condition(conditionType, status string) map[string]interface{} {
+	return map[string]interface{}{"type": conditionType, "status": status}
+}
+
+func record(phase string, injectedCount int64) map[string]interface{} {
+	return map[string]interface{}{"id": "default/pod", "phase": phase, "injectedCount": injectedCount}
+}
+
+func TestEvaluateRecords(t *testing.T) {
+	client := &Client{
+		logger: *log.WithFields(log.Fields{"test": "chaos"}),
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name             string
+		obj              *unstructured.Unstructured
+		expectedSuccess  bool
+		expectedFinished bool
+	}{
+		{
+			name: "targets not selected yet",
+			obj:  newRecordsExperiment("pod-failure", "1m", now, DesiredPhaseRun, nil),
+		},
+		{
+			name: "targets not selected without a reason",
+			obj: newRecordsExperiment("pod-failure", "1m", now, DesiredPhaseRun, []interface{}{
+				condition(ConditionSelected, "False"),
+			}),
+		},
+		{
+			name: "selection failed",
+			obj: newRecordsExperiment("pod-failure", "1m", now, DesiredPhaseRun, []interface{}{
+				map[string]interface{}{"type": ConditionSelected, "status": "False", "reason": "NoPodSelected"},
+			}),
+			expectedFinished: true,
+		},
+		{
+			name: "injected within the duration",
+			obj: newRecordsExperiment("pod-failure", "1m", now, DesiredPhaseRun, []interface{}{
+				condition(ConditionSelected, "True"), condition(ConditionAllInjected, "True"),
+			}, record("Injected", 1), record("Injected", 1)),
+		},
+		{
+			name: "stopped and recovered",
+			obj: newRecordsExperiment("pod-failure", "1m", now.Add(-2*time.Minute), DesiredPhaseStop, []interface{}{
+				condition(ConditionSelected, "True"), condition(ConditionAllRecovered, "True"),
+			}, record("NotInjected", 1), record("NotInjected", 1)),
+			expectedSuccess:  true,
+			expectedFinished: true,
+		},
+		{
+			name: "stopped while recovering",
+			obj: newRecordsExperiment("pod-failure", "1m", now.Add(-2*time.Minute), DesiredPhaseStop, []interface{}{
+				condition(ConditionSelected, "True"), condition(ConditionAllRecovered, "False"),
+			}, record("NotInjected", 1), record("Injected", 1)),
+		},
+		{
+			name: "duration elapsed before the desired phase flipped",
+			obj: newRecordsExperiment("pod-failure", "1m", now.Add(-2*time.Minute), DesiredPhaseRun, []interface{}{
+				condition(ConditionSelected, "True"),
+			}, record("NotInjected", 1)),
+			expectedSuccess:  true,
+			expectedFinished: true,
+		},
+		{
+			name: "target never injected",
+			obj: newRecordsExperiment("pod-failure", "1m", now.Add(-2*time.Minute), DesiredPhaseStop, []interface{}{
+				condition(ConditionSelected, "True"), condition(ConditionAllRecovered, "True"),
+			}, record("NotInjected", 1), record("NotInjected", 0)),
+			expectedFinished: true,
+		},
+		{
+			name: "paused",
+			obj: newRecordsExperiment("pod-failure", "1m", now.Add(-2*time.Minute), DesiredPhaseStop, []interface{}{
+				condition(ConditionPaused, "True"),
+			}, record("NotInjected", 1)),
+		},
+		{
+			name: "one-shot action without duration",
+			obj: newRecordsExperiment("pod-kill", "", now, DesiredPhaseRun, []interface{}{
+				condition(ConditionSelected, "True"), condition(ConditionAllInjected, "True"),
+			}, record("Injected", 1)),
+			expectedSuccess:  true,
+			expectedFinished: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			success, finished, err := client.checkExperimentStatus(tt.obj)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if success != tt.expectedSuccess {
+				t.Errorf("Expected success %v, got %v", tt.expectedSuccess, success)
+			}
+			if finished != tt.expectedFinished {
+				t.Errorf("Expected finished %v, got %v", tt.expectedFinished, finished)
+			}
+		})
+	}
+}
+
+func TestExperimentStatusSelectionFailed(t *testing.T) {
+	experiment := newRecordsExperiment("pod-failure", "1m", time.Now(), DesiredPhaseRun, []interface{}{
+		condition(ConditionSelected, "False"),
+	})
+	experiment.SetUID("uid-1")
+	client := newFakeClient(t, experiment)
+	ctx := context.Background()
+
+	success, finished, err := client.ExperimentStatus(ctx, experiment)
+	if err != nil || success || finished {
+		t.Fatalf("Expected the experiment to wait for its targets, got success=%t finished=%t err=%v", success, finished, err)
+	}
+
+	// Warnings unrelated to the selection do not fail the experiment
+	other := newEvent("other", "PodChaos", "test-chaos", "uid-1", EventTypeWarning, "Failed to apply chaos: timeout", "2024-01-01T10:00:00Z")
+	if _, err := client.dynamicClient.Resource(eventGVR).Namespace("default").Create(ctx, other, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if _, finished, _ := client.ExperimentStatus(ctx, experiment); finished {
+		t.Errorf("Expected an unrelated warning to keep the experiment waiting")
+	}
+
+	selection := newEvent("selection", "PodChaos", "test-chaos", "uid-1", EventTypeWarning, "Failed to select targets: no pod is selected", "2024-01-01T10:01:00Z")
+	if _, err := client.dynamicClient.Resource(eventGVR).Namespace("default").Create(ctx, selection, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	success, finished, err = client.ExperimentStatus(ctx, experiment)
+	if err != nil || success || !finished {
+		t.Errorf("Expected the controller warning to fail the experiment, got success=%t finished=%t err=%v", success, finished, err)
+	}
+}
+
+func TestFailureReason(t *testing.T) {
+	now := time.Now()
+	selectionFailed := condition(ConditionSelected, "False")
+	selectionFailed["message"] = "no pod is selected"
+
+	tests := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		expected string
+	}{
+		{
+			name:     "selection failed",
+			obj:      newRecordsExperiment("pod-failure", "1m", now, DesiredPhaseRun, []interface{}{selectionFailed}),
+			expected: "failed to select targets: no pod is selected",
+		},
+		{
+			name: "targets never injected",
+			obj: newRecordsExperiment("pod-failure", "1m", now, DesiredPhaseStop, nil,
+				record("NotInjected", 1), record("NotInjected", 0), record("NotInjected", 0)),
+			expected: "2 of 3 targets were never injected",
+		},
+		{
+			name:     "no reason",
+			obj:      newRecordsExperiment("pod-failure", "1m", now, DesiredPhaseStop, nil, record("NotInjected", 1)),
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if reason := FailureReason(tt.obj); reason != tt.expected {
+				t.Errorf("Expected reason '%s', got '%s'", tt.expected, reason)
+			}
+		})
+	}
+}
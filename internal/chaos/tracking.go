@@ -0,0 +1,182 @@
+package chaos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// LabelManagedBy marks every object created by the plugin
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+	// ManagedByValue is the value of LabelManagedBy on plugin-created objects
+	ManagedByValue = "argo-rollouts-chaos-mesh-plugin"
+
+	trackingPrefix = "chaos-mesh-plugin.argoproj.io/"
+
+	// LabelAnalysisRunUID holds the UID of the AnalysisRun that created the experiment
+	LabelAnalysisRunUID = trackingPrefix + "analysisrun-uid"
+	// LabelMetric holds the (label-safe) name of the metric that created the experiment
+	LabelMetric = trackingPrefix + "metric"
+	// LabelMeasurement identifies the single measurement that created the experiment
+	LabelMeasurement = trackingPrefix + "measurement"
+	// LabelStep holds the (label-safe) name of the experiment within a multi-experiment metric
+	LabelStep = trackingPrefix + "step"
+
+	// AnnotationAnalysisRun holds the namespace/name of the AnalysisRun that created the experiment
+	AnnotationAnalysisRun = trackingPrefix + "analysisrun"
+	// AnnotationMetric holds the unmodified metric name
+	AnnotationMetric = trackingPrefix + "metric"
+	// AnnotationDeadline holds the RFC3339 time after which the measurement times out
+	AnnotationDeadline = trackingPrefix + "deadline"
+
+	// AnnotationPause is the Chaos Mesh annotation that pauses an experiment
+	AnnotationPause = "experiment.chaos-mesh.org/pause"
+)
+
+// ExperimentRef identifies a single Chaos Mesh experiment
+type ExperimentRef struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	UID        types.UID
+}
+
+// RefFor returns the reference to an existing experiment object
+func RefFor(obj *unstructured.Unstructured) ExperimentRef {
+	return ExperimentRef{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		UID:        obj.GetUID(),
+	}
+}
+
+// String returns the kind and namespace/name of the experiment, e.g. PodChaos default/pod-kill
+func (r ExperimentRef) String() string {
+	return fmt.Sprintf("%s %s/%s", r.Kind, r.Namespace, r.Name)
+}
+
+// Tracking identifies the AnalysisRun measurement an experiment belongs to
+type Tracking struct {
+	AnalysisRunNamespace string
+	AnalysisRunName      string
+	AnalysisRunUID       string
+	MetricName           string
+	// MeasurementIndex is the position of the measurement within the metric's results
+	MeasurementIndex int
+	// Step names the experiment within a metric running several experiments
+	Step     string
+	Deadline time.Time
+}
+
+// Identity returns a short stable hash identifying the measurement. Retries of the same
+// measurement share it, while every other measurement gets a different one.
+func (t Tracking) Identity() string {
+	key := fmt.Sprintf("%s/%s/%d", t.AnalysisRunUID, t.MetricName, t.MeasurementIndex)
+	if t.Step != "" {
+		key += "/" + t.Step
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])[:10]
+}
+
+// IdentitySelector returns a label selector matching only the experiment of this measurement
+func (t Tracking) IdentitySelector() string {
+	set := labels.Set(t.Labels())
+	set[LabelMeasurement] = t.Identity()
+	return labels.SelectorFromSet(set).String()
+}
+
+// Labels returns the labels stamped on experiments created for this measurement
+func (t Tracking) Labels() map[string]string {
+	set := map[string]string{
+		LabelManagedBy:      ManagedByValue,
+		LabelAnalysisRunUID: labelValue(t.AnalysisRunUID),
+		LabelMetric:         labelValue(t.MetricName),
+	}
+	if t.Step != "" {
+		set[LabelStep] = labelValue(t.Step)
+	}
+	return set
+}
+
+// Annotations returns the annotations stamped on experiments created for this measurement
+func (t Tracking) Annotations() map[string]string {
+	annotations := map[string]string{
+		AnnotationAnalysisRun: t.AnalysisRunNamespace + "/" + t.AnalysisRunName,
+		AnnotationMetric:      t.MetricName,
+	}
+	if !t.Deadline.IsZero() {
+		annotations[AnnotationDeadline] = t.Deadline.UTC().Format(time.RFC3339)
+	}
+	return annotations
+}
+
+// Selector returns a label selector matching experiments created for this measurement
+func (t Tracking) Selector() string {
+	return labels.SelectorFromSet(t.Labels()).String()
+}
+
+// apply stamps the tracking labels and annotations onto obj, keeping existing ones
+func (t Tracking) apply(obj *unstructured.Unstructured) {
+	objLabels := obj.GetLabels()
+	if objLabels == nil {
+		objLabels = make(map[string]string)
+	}
+	for k, v := range t.Labels() {
+		objLabels[k] = v
+	}
+	objLabels[LabelMeasurement] = t.Identity()
+	obj.SetLabels(objLabels)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	for k, v := range t.Annotations() {
+		annotations[k] = v
+	}
+	obj.SetAnnotations(annotations)
+}
+
+// DeadlineOf returns the deadline recorded on an experiment by the plugin
+func DeadlineOf(obj *unstructured.Unstructured) (time.Time, bool) {
+	value, ok := obj.GetAnnotations()[AnnotationDeadline]
+	if !ok {
+		return time.Time{}, false
+	}
+	deadline, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return deadline, true
+}
+
+// suffixedName appends the measurement identity to name, shortening name so the result
+// stays a valid label value since Chaos Mesh copies experiment names into labels
+func suffixedName(name, identity string) string {
+	maxBase := validation.LabelValueMaxLength - len(identity) - 1
+	if len(name) > maxBase {
+		name = strings.TrimRight(name[:maxBase], "-.")
+	}
+	return name + "-" + identity
+}
+
+// labelValue returns s if it is a valid label value, or a stable hash of it otherwise
+func labelValue(s string) string {
+	if len(validation.IsValidLabelValue(s)) == 0 {
+		return s
+	}
+	sum := sha256.Sum256([]byte(s))
+	return "h-" + hex.EncodeToString(sum[:])[:32]
+}
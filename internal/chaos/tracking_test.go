@@ -0,0 +1,90 @@
+package chaos
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestTrackingApply(t *testing.T) {
+	deadline := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tracking := Tracking{
+		AnalysisRunNamespace: "team-a",
+		AnalysisRunName:      "rollout-abc-1",
+		AnalysisRunUID:       "1234-5678",
+		MetricName:           "chaos-mesh-test",
+		Deadline:             deadline,
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetLabels(map[string]string{"app": "payments"})
+	tracking.apply(obj)
+
+	labels := obj.GetLabels()
+	if labels["app"] != "payments" {
+		t.Errorf("Expected existing label to be kept, got %v", labels)
+	}
+	if labels[LabelAnalysisRunUID] != "1234-5678" {
+		t.Errorf("Expected %s to be '1234-5678', got '%s'", LabelAnalysisRunUID, labels[LabelAnalysisRunUID])
+	}
+	if labels[LabelManagedBy] != ManagedByValue {
+		t.Errorf("Expected %s to be '%s', got '%s'", LabelManagedBy, ManagedByValue, labels[LabelManagedBy])
+	}
+
+	if obj.GetAnnotations()[AnnotationAnalysisRun] != "team-a/rollout-abc-1" {
+		t.Errorf("Expected %s to be 'team-a/rollout-abc-1', got '%s'", AnnotationAnalysisRun, obj.GetAnnotations()[AnnotationAnalysisRun])
+	}
+
+	got, ok := DeadlineOf(obj)
+	if !ok || !got.Equal(deadline) {
+		t.Errorf("Expected deadline %v, got %v (found=%t)", deadline, got, ok)
+	}
+}
+
+func TestLabelValue(t *testing.T) {
+	if got := labelValue("chaos-mesh-test"); got != "chaos-mesh-test" {
+		t.Errorf("Expected valid label value to be kept, got '%s'", got)
+	}
+
+	long := strings.Repeat("metric ", 20)
+	got := labelValue(long)
+	if errs := validation.IsValidLabelValue(got); len(errs) != 0 {
+		t.Errorf("Expected hashed value to be a valid label value, got '%s': %v", got, errs)
+	}
+	if got != labelValue(long) {
+		t.Errorf("Expected hashed value to be stable")
+	}
+}
+
+func TestTrackingIdentity(t *testing.T) {
+	first := Tracking{AnalysisRunUID: "1234-5678", MetricName: "chaos-mesh-test"}
+	second := first
+	second.MeasurementIndex = 1
+
+	if first.Identity() != first.Identity() {
+		t.Errorf("Expected identity to be stable")
+	}
+	if first.Identity() == second.Identity() {
+		t.Errorf("Expected measurements to have different identities, both got '%s'", first.Identity())
+	}
+	if !strings.Contains(first.IdentitySelector(), LabelMeasurement+"="+first.Identity()) {
+		t.Errorf("Expected identity selector to match %s, got '%s'", LabelMeasurement, first.IdentitySelector())
+	}
+}
+
+func TestSuffixedName(t *testing.T) {
+	if got := suffixedName("pod-kill", "abc123"); got != "pod-kill-abc123" {
+		t.Errorf("Expected 'pod-kill-abc123', got '%s'", got)
+	}
+
+	got := suffixedName(strings.Repeat("a", 60)+"-b", "abc123")
+	if len(got) > validation.LabelValueMaxLength {
+		t.Errorf("Expected name of at most %d characters, got %d", validation.LabelValueMaxLength, len(got))
+	}
+	if !strings.HasSuffix(got, "-abc123") {
+		t.Errorf("Expected name to keep the identity suffix, got '%s'", got)
+	}
+}
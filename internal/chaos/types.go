@@ -43,6 +43,13 @@ type Selector struct {
 	ExpressionSelectors []ExpressionSelector `json:"expressionSelectors,omitempty"`
 }
 
+// PodSelector represents the selector and mode of the pods on the other side of a NetworkChaos
+type PodSelector struct {
+	Selector Selector `json:"selector"`
+	Mode     string   `json:"mode"`
+	Value    string   `json:"value,omitempty"`
+}
+
 // ExpressionSelector represents a label selector requirement
 type ExpressionSelector struct {
 	Key      string   `json:"key"`
@@ -68,7 +75,7 @@ type NetworkChaosSpec struct {
 	Value     string   `json:"value,omitempty"`
 	Duration  string   `json:"duration,omitempty"`
 	Direction string   `json:"direction,omitempty"`
-	Target    *Selector `json:"target,omitempty"`
+	Target    *PodSelector `json:"target,omitempty"`
 	Delay     *DelaySpec `json:"delay,omitempty"`
 	Loss      *LossSpec  `json:"loss,omitempty"`
 	Duplicate *DuplicateSpec `json:"duplicate,omitempty"`
@@ -76,7 +83,8 @@ type NetworkChaosSpec struct {
 	Bandwidth *BandwidthSpec `json:"bandwidth,omitempty"`
 }
 
-// Scheduler represents the scheduler configuration
+// Scheduler represents the scheduler configuration of Chaos Mesh 1.x. Chaos Mesh 2.x runs
+// recurring chaos through Schedule objects instead, see KindSchedule.
 type Scheduler struct {
 	Cron string `json:"cron,omitempty"`
 }
@@ -0,0 +1,271 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// watchBackoff spaces out retries after failed watches and reads
+var watchBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    10,
+	Cap:      30 * time.Second,
+}
+
+// pollInterval is how often the experiment is read once watching it keeps failing
+var pollInterval = 10 * time.Second
+
+// maxWatchFailures is the number of consecutive failed watches after which WatchExperiment
+// falls back to polling
+const maxWatchFailures = 5
+
+// minHealthyWatch is how long a watch without events has to last for its end to count as a
+// routine close rather than a failure
+const minHealthyWatch = time.Second
+
+// errWatchClosed is reported when the API server ends a watch, e.g. on its timeout or restart
+var errWatchClosed = fmt.Errorf("watch channel closed unexpectedly")
+
+// WatchExperiment watches a Chaos Mesh experiment until completion or timeout and returns
+// the experiment as last observed. Cancelling ctx stops the watch immediately.
+//
+// Experiments are observed through an informer shared by every experiment of the same kind
+// and namespace, so concurrent analyses cost one watch stream per kind. When the informer
+// cannot list the experiments in time, e.g. without list permission, the experiment is
+// watched on its own instead.
+func (c *Client) WatchExperiment(ctx context.Context, ref ExperimentRef, timeout time.Duration) (*unstructured.Unstructured, error) {
+	gvr, err := c.getGVR(ref.APIVersion, ref.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.Infof("Watching Chaos Mesh experiment: %s/%s", ref.Namespace, ref.Name)
+
+	// Create a context with timeout
+	watchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	sub := c.informers.subscribe(c.dynamicClient, gvr, ref)
+	defer c.informers.unsubscribe(sub)
+
+	if !sub.waitForSync(watchCtx, informerSyncTimeout) {
+		if watchCtx.Err() != nil {
+			return nil, watchEnded(ctx)
+		}
+		c.logger.Warnf("Informer for %s in %s did not sync, watching experiment %s on its own", gvr.Resource, ref.Namespace, ref.Name)
+		return c.watchExperiment(ctx, watchCtx, gvr, ref)
+	}
+
+	for {
+		obj, err := sub.get()
+		if errors.IsNotFound(err) {
+			// The cache may not have caught up with a new experiment yet
+			obj, err = c.GetExperiment(watchCtx, ref)
+		}
+		switch {
+		case errors.IsNotFound(err):
+			return nil, err
+		case err != nil:
+			c.logger.Warnf("Error reading experiment %s/%s from cache: %v", ref.Namespace, ref.Name, err)
+		case c.experimentFinished(watchCtx, ref, obj):
+			return obj, nil
+		}
+
+		select {
+		case <-sub.changed:
+		case <-watchCtx.Done():
+			return nil, watchEnded(ctx)
+		}
+	}
+}
+
+// watchExperiment watches a single experiment until it finishes or watchCtx is done.
+//
+// Watches ended by the API server are resumed from the last seen resourceVersion, and the
+// experiment is read again when that version expired (410 Gone). Transient errors are retried
+// with backoff, and once watching keeps failing the experiment is polled instead.
+func (c *Client) watchExperiment(ctx, watchCtx context.Context, gvr schema.GroupVersionResource, ref ExperimentRef) (*unstructured.Unstructured, error) {
+	backoff := watchBackoff
+	resourceVersion := ""
+	failures := 0
+	for {
+		if failures >= maxWatchFailures {
+			c.logger.Warnf("Watching experiment %s/%s keeps failing, polling every %s", ref.Namespace, ref.Name, pollInterval)
+			return c.pollExperiment(ctx, watchCtx, ref)
+		}
+
+		// (Re)read the experiment to catch up with changes made while not watching
+		if resourceVersion == "" {
+			obj, err := c.GetExperiment(watchCtx, ref)
+			switch {
+			case errors.IsNotFound(err):
+				return nil, err
+			case err != nil:
+				c.logger.Warnf("Failed to get experiment %s/%s, retrying: %v", ref.Namespace, ref.Name, err)
+				failures++
+				if err := sleep(ctx, watchCtx, backoff.Step()); err != nil {
+					return nil, err
+				}
+				continue
+			case c.experimentFinished(watchCtx, ref, obj):
+				return obj, nil
+			}
+			resourceVersion = obj.GetResourceVersion()
+		}
+
+		started := time.Now()
+		watcher, err := c.dynamicClient.Resource(gvr).Namespace(ref.Namespace).Watch(watchCtx, metav1.ListOptions{
+			FieldSelector:       fields.OneTermEqualSelector("metadata.name", ref.Name).String(),
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		})
+		if err == nil {
+			var obj *unstructured.Unstructured
+			var received bool
+			obj, resourceVersion, received, err = c.consumeWatch(ctx, watchCtx, watcher, gvr.GroupResource(), ref, resourceVersion)
+			watcher.Stop()
+			if obj != nil {
+				return obj, nil
+			}
+			if received || time.Since(started) >= minHealthyWatch {
+				// The watch was healthy, so start over with a fresh budget
+				failures = 0
+				backoff = watchBackoff
+				if err == errWatchClosed {
+					c.logger.Debugf("Watch of experiment %s/%s closed, resuming from version %s", ref.Namespace, ref.Name, resourceVersion)
+					continue
+				}
+			}
+		}
+
+		switch {
+		case watchCtx.Err() != nil:
+			return nil, watchEnded(ctx)
+		case errors.IsNotFound(err):
+			return nil, err
+		case isExpired(err):
+			c.logger.Infof("Watch of experiment %s/%s expired, reading it again", ref.Namespace, ref.Name)
+			resourceVersion = ""
+			continue
+		}
+		c.logger.Warnf("Watch of experiment %s/%s failed, retrying: %v", ref.Namespace, ref.Name, err)
+		failures++
+		if err := sleep(ctx, watchCtx, backoff.Step()); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// consumeWatch reads watch events until the experiment finishes, the watch ends or fails. It
+// returns the finished experiment, the last resourceVersion seen and whether any event was
+// received.
+func (c *Client) consumeWatch(ctx, watchCtx context.Context, watcher watch.Interface, resource schema.GroupResource, ref ExperimentRef, resourceVersion string) (*unstructured.Unstructured, string, bool, error) {
+	received := false
+	for {
+		select {
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil, resourceVersion, received, errWatchClosed
+			}
+			received = true
+
+			if event.Type == watch.Error {
+				return nil, resourceVersion, received, errors.FromObject(event.Object)
+			}
+
+			obj, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			if obj.GetResourceVersion() != "" {
+				resourceVersion = obj.GetResourceVersion()
+			}
+			// Not every API server honours the field selector, so check the name as well
+			if event.Type == watch.Bookmark || obj.GetName() != ref.Name || (ref.UID != "" && obj.GetUID() != ref.UID) {
+				continue
+			}
+			if event.Type == watch.Deleted {
+				return nil, resourceVersion, received, errors.NewNotFound(resource, ref.Name)
+			}
+
+			if c.experimentFinished(watchCtx, ref, obj) {
+				return obj, resourceVersion, received, nil
+			}
+
+		case <-watchCtx.Done():
+			return nil, resourceVersion, received, watchEnded(ctx)
+		}
+	}
+}
+
+// pollExperiment reads the experiment every pollInterval until it finishes
+func (c *Client) pollExperiment(ctx, watchCtx context.Context, ref ExperimentRef) (*unstructured.Unstructured, error) {
+	for {
+		obj, err := c.GetExperiment(watchCtx, ref)
+		switch {
+		case errors.IsNotFound(err):
+			return nil, err
+		case err != nil:
+			if watchCtx.Err() != nil {
+				return nil, watchEnded(ctx)
+			}
+			c.logger.Warnf("Failed to get experiment %s/%s, retrying: %v", ref.Namespace, ref.Name, err)
+		case c.experimentFinished(watchCtx, ref, obj):
+			return obj, nil
+		}
+
+		if err := sleep(ctx, watchCtx, pollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// experimentFinished reports whether the observed experiment has finished
+func (c *Client) experimentFinished(ctx context.Context, ref ExperimentRef, obj *unstructured.Unstructured) bool {
+	success, finished, err := c.ExperimentStatus(ctx, obj)
+	if err != nil {
+		c.logger.Warnf("Error checking experiment status: %v", err)
+		return false
+	}
+	if finished {
+		c.logger.Infof("Experiment %s/%s finished with success=%t", ref.Namespace, ref.Name, success)
+	}
+	return finished
+}
+
+// sleep waits for d unless the watch ends first
+func sleep(ctx, watchCtx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-watchCtx.Done():
+		return watchEnded(ctx)
+	}
+}
+
+// watchEnded returns the error reported once the watch context is done: ctx was cancelled
+// or the timeout passed
+func watchEnded(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("watch cancelled: %w", ctx.Err())
+	}
+	return fmt.Errorf("timeout waiting for experiment to complete")
+}
+
+// isExpired reports whether the resourceVersion a watch started from is too old (410 Gone)
+func isExpired(err error) bool {
+	return errors.IsResourceExpired(err) || errors.IsGone(err)
+}
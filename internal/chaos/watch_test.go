@@ -0,0 +1,201 @@
+package chaos
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// fastWatchRetries shortens the watch backoff and polling interval for the duration of a test
+func fastWatchRetries(t *testing.T) {
+	backoff, interval := watchBackoff, pollInterval
+	watchBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 1}
+	pollInterval = time.Millisecond
+	t.Cleanup(func() {
+		watchBackoff, pollInterval = backoff, interval
+	})
+}
+
+func newWatchedExperiment(phase, resourceVersion string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "chaos-mesh.org/v1alpha1",
+		"kind":       "PodChaos",
+		"metadata": map[string]interface{}{
+			"name":            "test-chaos",
+			"namespace":       "default",
+			"resourceVersion": resourceVersion,
+			"labels":          map[string]interface{}{LabelManagedBy: ManagedByValue},
+		},
+		"status": map[string]interface{}{
+			"experiment": map[string]interface{}{"phase": phase},
+		},
+	}}
+}
+
+var watchedRef = ExperimentRef{APIVersion: "chaos-mesh.org/v1alpha1", Kind: "PodChaos", Namespace: "default", Name: "test-chaos"}
+
+var podChaosGVR = schema.GroupVersionResource{Group: "chaos-mesh.org", Version: "v1alpha1", Resource: "podchaos"}
+
+// watchOnItsOwn watches the test experiment without a shared informer
+func watchOnItsOwn(ctx context.Context, client *Client, timeout time.Duration) (*unstructured.Unstructured, error) {
+	watchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return client.watchExperiment(ctx, watchCtx, podChaosGVR, watchedRef)
+}
+
+// watchResourceVersions returns the resourceVersion each watch of the fake client started from
+func watchResourceVersions(dynamicClient *fake.FakeDynamicClient) []string {
+	var versions []string
+	for _, action := range dynamicClient.Actions() {
+		if watchAction, ok := action.(k8stesting.WatchActionImpl); ok {
+			versions = append(versions, watchAction.GetWatchRestrictions().ResourceVersion)
+		}
+	}
+	return versions
+}
+
+func countActions(dynamicClient *fake.FakeDynamicClient, verb string) int {
+	count := 0
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() == verb {
+			count++
+		}
+	}
+	return count
+}
+
+func TestWatchExperimentResumesClosedWatch(t *testing.T) {
+	fastWatchRetries(t)
+	client := newFakeClient(t, newWatchedExperiment("Running", "1"))
+	dynamicClient := client.dynamicClient.(*fake.FakeDynamicClient)
+
+	watches := 0
+	dynamicClient.PrependWatchReactor("podchaos", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watches++
+		watcher := watch.NewFakeWithChanSize(1, false)
+		if watches == 1 {
+			// The API server ends the first watch after one update
+			watcher.Modify(newWatchedExperiment("Running", "5"))
+			watcher.Stop()
+		} else {
+			watcher.Modify(newWatchedExperiment("Finished", "6"))
+		}
+		return true, watcher, nil
+	})
+
+	obj, err := watchOnItsOwn(context.Background(), client, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if obj.GetResourceVersion() != "6" {
+		t.Errorf("Expected the finished experiment, got version %s", obj.GetResourceVersion())
+	}
+
+	versions := watchResourceVersions(dynamicClient)
+	if len(versions) != 2 || versions[0] != "1" || versions[1] != "5" {
+		t.Errorf("Expected watches from versions [1 5], got %v", versions)
+	}
+	if gets := countActions(dynamicClient, "get"); gets != 1 {
+		t.Errorf("Expected the resumed watch not to read the experiment again, got %d reads", gets)
+	}
+}
+
+func TestWatchExperimentRelistsExpiredVersion(t *testing.T) {
+	fastWatchRetries(t)
+	client := newFakeClient(t, newWatchedExperiment("Running", "1"))
+	dynamicClient := client.dynamicClient.(*fake.FakeDynamicClient)
+
+	watches := 0
+	dynamicClient.PrependWatchReactor("podchaos", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watches++
+		watcher := watch.NewFakeWithChanSize(1, false)
+		if watches == 1 {
+			expired := errors.NewResourceExpired("too old resource version")
+			watcher.Error(&expired.ErrStatus)
+		} else {
+			watcher.Modify(newWatchedExperiment("Finished", "9"))
+		}
+		return true, watcher, nil
+	})
+
+	if _, err := watchOnItsOwn(context.Background(), client, 5*time.Second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gets := countActions(dynamicClient, "get"); gets != 2 {
+		t.Errorf("Expected the experiment to be read again after 410 Gone, got %d reads", gets)
+	}
+}
+
+func TestWatchExperimentFallsBackToPolling(t *testing.T) {
+	fastWatchRetries(t)
+	client := newFakeClient(t, newWatchedExperiment("Running", "1"))
+	dynamicClient := client.dynamicClient.(*fake.FakeDynamicClient)
+	gvr := podChaosGVR
+
+	watches := 0
+	dynamicClient.PrependWatchReactor("podchaos", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watches++
+		if watches == maxWatchFailures {
+			// The experiment finishes while watching is not possible
+			if err := dynamicClient.Tracker().Update(gvr, newWatchedExperiment("Finished", "2"), "default"); err != nil {
+				t.Errorf("Failed to update experiment: %v", err)
+			}
+		}
+		return true, nil, errors.NewForbidden(gvr.GroupResource(), "", nil)
+	})
+
+	obj, err := watchOnItsOwn(context.Background(), client, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "experiment", "phase")
+	if phase != "Finished" {
+		t.Errorf("Expected the polled experiment to be finished, got %s", phase)
+	}
+	if watches != maxWatchFailures {
+		t.Errorf("Expected %d watch attempts before polling, got %d", maxWatchFailures, watches)
+	}
+}
+
+func TestWatchExperimentTimeout(t *testing.T) {
+	fastWatchRetries(t)
+	client := newFakeClient(t, newWatchedExperiment("Running", "1"))
+
+	_, err := watchOnItsOwn(context.Background(), client, 50*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timeout waiting for experiment to complete") {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = watchOnItsOwn(ctx, client, time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "watch cancelled") {
+		t.Errorf("Expected a cancellation error, got %v", err)
+	}
+}
+
+func TestWatchExperimentDeleted(t *testing.T) {
+	fastWatchRetries(t)
+	client := newFakeClient(t, newWatchedExperiment("Running", "1"))
+	dynamicClient := client.dynamicClient.(*fake.FakeDynamicClient)
+
+	dynamicClient.PrependWatchReactor("podchaos", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watcher := watch.NewFakeWithChanSize(1, false)
+		watcher.Delete(newWatchedExperiment("Running", "2"))
+		return true, watcher, nil
+	})
+
+	_, err := watchOnItsOwn(context.Background(), client, 5*time.Second)
+	if !errors.IsNotFound(err) {
+		t.Errorf("Expected NotFound once the experiment is deleted, got %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// KindWorkflow is the kind of Chaos Mesh workflows
+	KindWorkflow = "Workflow"
+	// KindWorkflowNode is the kind of the nodes Chaos Mesh creates while running a workflow
+	KindWorkflowNode = "WorkflowNode"
+
+	// LabelWorkflow is set by Chaos Mesh on every node of a workflow
+	LabelWorkflow = "chaos-mesh.org/workflow"
+	// LabelControlledBy is set by Chaos Mesh on workflow nodes created by another node
+	LabelControlledBy = "chaos-mesh.org/controlled-by"
+	// AnnotationAbort is the Chaos Mesh annotation that aborts a workflow
+	AnnotationAbort = "workflow.chaos-mesh.org/abort"
+)
+
+// Workflow node phases reported by WorkflowNodes
+const (
+	NodePhaseRunning          = "Running"
+	NodePhaseSucceeded        = "Succeeded"
+	NodePhaseDeadlineExceeded = "DeadlineExceeded"
+	NodePhaseAborted          = "Aborted"
+)
+
+// WorkflowNodeStatus summarises one node of a running or finished workflow
+type WorkflowNodeStatus struct {
+	Name     string `json:"name"`
+	Template string `json:"template"`
+	Type     string `json:"type"`
+	Parent   string `json:"parent,omitempty"`
+	Phase    string `json:"phase"`
+	// Chaos is the kind/name of the chaos object created by a chaos node
+	Chaos string `json:"chaos,omitempty"`
+}
+
+// WorkflowNodes returns the nodes Chaos Mesh created for a workflow, sorted by name
+func (c *Client) WorkflowNodes(ctx context.Context, ref ExperimentRef) ([]WorkflowNodeStatus, error) {
+	gvr, err := c.getGVR(ref.APIVersion, KindWorkflowNode)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := c.dynamicClient.Resource(gvr).Namespace(ref.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{LabelWorkflow: ref.Name}).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflow nodes: %w", err)
+	}
+
+	nodes := make([]WorkflowNodeStatus, 0, len(list.Items))
+	for i := range list.Items {
+		nodes = append(nodes, workflowNodeStatus(&list.Items[i]))
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes, nil
+}
+
+// workflowNodeStatus summarises a WorkflowNode object
+func workflowNodeStatus(obj *unstructured.Unstructured) WorkflowNodeStatus {
+	node := WorkflowNodeStatus{
+		Name:   obj.GetName(),
+		Parent: obj.GetLabels()[LabelControlledBy],
+		Phase:  NodePhaseRunning,
+	}
+	node.Template, _, _ = unstructured.NestedString(obj.Object, "spec", "templateName")
+	node.Type, _, _ = unstructured.NestedString(obj.Object, "spec", "type")
+
+	kind, _, _ := unstructured.NestedString(obj.Object, "status", "chaosResource", "kind")
+	name, _, _ := unstructured.NestedString(obj.Object, "status", "chaosResource", "name")
+	if kind != "" && name != "" {
+		node.Chaos = kind + "/" + name
+	}
+
+	switch {
+	case conditionTrue(obj, "Aborted"):
+		node.Phase = NodePhaseAborted
+	case conditionTrue(obj, "Accomplished"):
+		node.Phase = NodePhaseSucceeded
+	case conditionTrue(obj, "DeadlineExceed"):
+		node.Phase = NodePhaseDeadlineExceeded
+	}
+	return node
+}
+
+// workflowStatus decides whether a workflow has finished from its Accomplished condition. A
+// workflow has no condition for aborts, which Chaos Mesh reports on its nodes instead, see
+// WorkflowAborted.
+func workflowStatus(obj *unstructured.Unstructured) (success bool, finished bool, err error) {
+	if _, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions"); err != nil {
+		return false, false, fmt.Errorf("failed to get conditions: %w", err)
+	}
+	accomplished := conditionTrue(obj, "Accomplished")
+	return accomplished, accomplished, nil
+}
+
+// WorkflowAborted reports whether the entry node of a workflow, the only node no other node
+// created, was aborted, which fails the whole workflow
+func WorkflowAborted(nodes []WorkflowNodeStatus) bool {
+	for _, node := range nodes {
+		if node.Parent == "" && node.Phase == NodePhaseAborted {
+			return true
+		}
+	}
+	return false
+}
+
+// injectWorkflowSelector injects the target selector into every chaos template of a workflow,
+// including the chaos embedded in Schedule templates
+func (c *Client) injectWorkflowSelector(obj *unstructured.Unstructured, injection *selectorInjection) error {
+	spec, found, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return fmt.Errorf("failed to get spec: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("spec not found in workflow")
+	}
+
+	if err := injection.injectTemplates(spec, "spec"); err != nil {
+		return err
+	}
+	return unstructured.SetNestedMap(obj.Object, spec, "spec")
+}
+
+// injectTemplates injects the target selector into the templates of the workflow spec at path
+func (in *selectorInjection) injectTemplates(workflowSpec map[string]interface{}, path string) error {
+	templates, found, err := unstructured.NestedSlice(workflowSpec, "templates")
+	if err != nil {
+		return fmt.Errorf("failed to get workflow templates: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("workflow has no templates")
+	}
+
+	for i, item := range templates {
+		template, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(template, "name")
+		templateType, _, _ := unstructured.NestedString(template, "templateType")
+		templatePath := fmt.Sprintf("%s.templates[%s]", path, name)
+
+		if templateType == KindSchedule {
+			schedule, _, _ := unstructured.NestedMap(template, "schedule")
+			scheduleType, _, _ := unstructured.NestedString(schedule, "type")
+			if err := in.injectEmbedded(schedule, scheduleType, templatePath+".schedule"); err != nil {
+				return fmt.Errorf("workflow template %s: %w", name, err)
+			}
+			template["schedule"] = schedule
+		} else if err := in.injectEmbedded(template, templateType, templatePath); err != nil {
+			return fmt.Errorf("workflow template %s: %w", name, err)
+		}
+		templates[i] = template
+	}
+
+	workflowSpec["templates"] = templates
+	return nil
+}
+
+// injectEmbedded injects the target selector into the chaos or workflow of the given kind
+// embedded in the spec at path, as workflow templates and schedules embed them. Other kinds,
+// e.g. Serial or Suspend templates and chaos kinds that do not select pods, are left untouched.
+func (in *selectorInjection) injectEmbedded(spec map[string]interface{}, kind string, path string) error {
+	switch {
+	case kind == KindWorkflow:
+		workflow, found, err := unstructured.NestedMap(spec, "workflow")
+		if err != nil || !found {
+			return fmt.Errorf("no workflow embedded")
+		}
+		if err := in.injectTemplates(workflow, path+".workflow"); err != nil {
+			return err
+		}
+		spec["workflow"] = workflow
+	case isChaosKind(kind) && traitsOf(kind).selectsPods:
+		field := embeddedChaosField(kind)
+		chaosSpec, found, err := unstructured.NestedMap(spec, field)
+		if err != nil || !found {
+			return fmt.Errorf("no %s embedded", field)
+		}
+		if err := in.injectChaos(chaosSpec, kind, path+"."+field); err != nil {
+			return err
+		}
+		spec[field] = chaosSpec
+	}
+	return nil
+}
+
+// embeddedChaosField returns the field holding the spec of a chaos kind embedded in a
+// workflow or schedule, e.g. podChaos for PodChaos and ioChaos for IOChaos
+func embeddedChaosField(kind string) string {
+	return strings.ToLower(strings.TrimSuffix(kind, "Chaos")) + "Chaos"
+}
+
+// conditionTrue reports whether the status condition of the given type is True
+func conditionTrue(obj *unstructured.Unstructured, conditionType string) bool {
+	condition, found := findCondition(obj, conditionType)
+	return found && condition["status"] == "True"
+}
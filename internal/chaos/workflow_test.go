@@ -0,0 +1,260 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newWorkflow() *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "chaos-mesh.org/v1alpha1",
+			"kind":       "Workflow",
+			"metadata": map[string]interface{}{
+				"name":      "canary-scenario",
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"entry": "entry",
+				"templates": []interface{}{
+					map[string]interface{}{
+						"name":         "entry",
+						"templateType": "Serial",
+						"children":     []interface{}{"kill-pod", "delay-network", "suspend"},
+					},
+					map[string]interface{}{
+						"name":         "kill-pod",
+						"templateType": "PodChaos",
+						"podChaos": map[string]interface{}{
+							"action":   "pod-kill",
+							"mode":     "one",
+							"selector": map[string]interface{}{"namespaces": []interface{}{"default"}},
+						},
+					},
+					map[string]interface{}{
+						"name":         "delay-network",
+						"templateType": "Schedule",
+						"schedule": map[string]interface{}{
+							"schedule": "@every 30s",
+							"type":     "NetworkChaos",
+							"networkChaos": map[string]interface{}{
+								"action":   "delay",
+								"mode":     "all",
+								"selector": map[string]interface{}{"namespaces": []interface{}{"default"}},
+							},
+						},
+					},
+					map[string]interface{}{
+						"name":         "suspend",
+						"templateType": "Suspend",
+						"deadline":     "30s",
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestInjectWorkflowSelector(t *testing.T) {
+	client := &Client{
+		logger: *log.WithFields(log.Fields{"test": "chaos"}),
+	}
+	obj := newWorkflow()
+
+	if _, err := client.injectSelector(obj, TargetSelector{Labels: map[string]string{"app": "test-app"}}); err != nil {
+		t.Fatalf("Failed to inject selector: %v", err)
+	}
+
+	templates, _, _ := unstructured.NestedSlice(obj.Object, "spec", "templates")
+	tests := []struct {
+		template int
+		path     []string
+	}{
+		{template: 1, path: []string{"podChaos", "selector", "labelSelectors", "app"}},
+		{template: 2, path: []string{"schedule", "networkChaos", "selector", "labelSelectors", "app"}},
+	}
+	for _, tt := range tests {
+		template := templates[tt.template].(map[string]interface{})
+		value, _, _ := unstructured.NestedString(template, tt.path...)
+		if value != "test-app" {
+			t.Errorf("Expected %v of template %v to be 'test-app', got '%v'", tt.path, template["name"], value)
+		}
+	}
+
+	if _, found := templates[3].(map[string]interface{})["selector"]; found {
+		t.Errorf("Expected suspend template to be left untouched, got %v", templates[3])
+	}
+}
+
+func TestEmbeddedChaosField(t *testing.T) {
+	tests := map[string]string{
+		"PodChaos":     "podChaos",
+		"NetworkChaos": "networkChaos",
+		"IOChaos":      "ioChaos",
+		"HTTPChaos":    "httpChaos",
+		"JVMChaos":     "jvmChaos",
+	}
+	for kind, expected := range tests {
+		if field := embeddedChaosField(kind); field != expected {
+			t.Errorf("Expected field %s for %s, got %s", expected, kind, field)
+		}
+	}
+}
+
+func TestWorkflowStatus(t *testing.T) {
+	tests := []struct {
+		name             string
+		conditions       []interface{}
+		expectedSuccess  bool
+		expectedFinished bool
+	}{
+		{
+			name:             "no conditions",
+			expectedSuccess:  false,
+			expectedFinished: false,
+		},
+		{
+			name: "accomplished",
+			conditions: []interface{}{
+				map[string]interface{}{"type": "Accomplished", "status": "True"},
+				map[string]interface{}{"type": "Scheduled", "status": "True"},
+			},
+			expectedSuccess:  true,
+			expectedFinished: true,
+		},
+		{
+			name: "running",
+			conditions: []interface{}{
+				map[string]interface{}{"type": "Accomplished", "status": "False"},
+			},
+			expectedSuccess:  false,
+			expectedFinished: false,
+		},
+	}
+
+	client := &Client{
+		logger: *log.WithFields(log.Fields{"test": "chaos"}),
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := newWorkflow()
+			if tt.conditions != nil {
+				_ = unstructured.SetNestedSlice(obj.Object, tt.conditions, "status", "conditions")
+			}
+
+			success, finished, err := client.ExperimentStatus(context.Background(), obj)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if success != tt.expectedSuccess {
+				t.Errorf("Expected success %v, got %v", tt.expectedSuccess, success)
+			}
+			if finished != tt.expectedFinished {
+				t.Errorf("Expected finished %v, got %v", tt.expectedFinished, finished)
+			}
+		})
+	}
+}
+
+func TestWorkflowAborted(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodes    []WorkflowNodeStatus
+		expected bool
+	}{
+		{name: "no nodes"},
+		{
+			name:  "running",
+			nodes: []WorkflowNodeStatus{{Name: "entry", Phase: NodePhaseRunning}, {Name: "kill", Parent: "entry", Phase: NodePhaseRunning}},
+		},
+		{
+			name:     "entry node aborted",
+			nodes:    []WorkflowNodeStatus{{Name: "entry", Phase: NodePhaseAborted}, {Name: "kill", Parent: "entry", Phase: NodePhaseRunning}},
+			expected: true,
+		},
+		{
+			name:  "child node aborted",
+			nodes: []WorkflowNodeStatus{{Name: "entry", Phase: NodePhaseRunning}, {Name: "kill", Parent: "entry", Phase: NodePhaseAborted}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if aborted := WorkflowAborted(tt.nodes); aborted != tt.expected {
+				t.Errorf("Expected aborted %v, got %v", tt.expected, aborted)
+			}
+		})
+	}
+}
+
+func TestWorkflowNodes(t *testing.T) {
+	newNode := func(name, workflow, parent, templateType string, condition string, chaosKind string) *unstructured.Unstructured {
+		labels := map[string]interface{}{LabelWorkflow: workflow}
+		if parent != "" {
+			labels[LabelControlledBy] = parent
+		}
+		obj := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "chaos-mesh.org/v1alpha1",
+				"kind":       "WorkflowNode",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": "default",
+					"labels":    labels,
+				},
+				"spec": map[string]interface{}{
+					"templateName": name[:len(name)-6],
+					"type":         templateType,
+				},
+			},
+		}
+		if condition != "" {
+			_ = unstructured.SetNestedSlice(obj.Object, []interface{}{
+				map[string]interface{}{"type": condition, "status": "True"},
+			}, "status", "conditions")
+		}
+		if chaosKind != "" {
+			_ = unstructured.SetNestedMap(obj.Object, map[string]interface{}{
+				"kind": chaosKind,
+				"name": name,
+			}, "status", "chaosResource")
+		}
+		return obj
+	}
+
+	client := newFakeClient(t,
+		newNode("entry-aaaaa", "canary-scenario", "", "Serial", "", ""),
+		newNode("kill-pod-bbbbb", "canary-scenario", "entry-aaaaa", "PodChaos", "Accomplished", "PodChaos"),
+		newNode("suspend-ccccc", "canary-scenario", "entry-aaaaa", "Suspend", "DeadlineExceed", ""),
+		newNode("abort-ddddd", "canary-scenario", "entry-aaaaa", "Task", "Aborted", ""),
+		newNode("other-eeeee", "other-scenario", "", "Serial", "", ""),
+	)
+
+	nodes, err := client.WorkflowNodes(context.Background(), ExperimentRef{
+		APIVersion: "chaos-mesh.org/v1alpha1",
+		Kind:       KindWorkflow,
+		Namespace:  "default",
+		Name:       "canary-scenario",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []WorkflowNodeStatus{
+		{Name: "abort-ddddd", Template: "abort", Type: "Task", Parent: "entry-aaaaa", Phase: NodePhaseAborted},
+		{Name: "entry-aaaaa", Template: "entry", Type: "Serial", Phase: NodePhaseRunning},
+		{Name: "kill-pod-bbbbb", Template: "kill-pod", Type: "PodChaos", Parent: "entry-aaaaa", Phase: NodePhaseSucceeded, Chaos: "PodChaos/kill-pod-bbbbb"},
+		{Name: "suspend-ccccc", Template: "suspend", Type: "Suspend", Parent: "entry-aaaaa", Phase: NodePhaseDeadlineExceeded},
+	}
+	if len(nodes) != len(expected) {
+		t.Fatalf("Expected %d nodes, got %d: %v", len(expected), len(nodes), nodes)
+	}
+	for i := range expected {
+		if nodes[i] != expected[i] {
+			t.Errorf("Expected node %d to be %+v, got %+v", i, expected[i], nodes[i])
+		}
+	}
+}
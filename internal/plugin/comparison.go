@@ -0,0 +1,124 @@
+package plugin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
+)
+
+// Comparison rules decide whether the canary degraded no worse than the baseline when every
+// experiment runs against both
+const (
+	// ComparisonSuccess fails the measurement when the experiments fail against the canary
+	// but succeed against the baseline
+	ComparisonSuccess = "success"
+	// ComparisonRecovery also fails the measurement when the canary takes longer than the
+	// baseline, plus the comparison tolerance, to recover
+	ComparisonRecovery = "recovery"
+)
+
+// Roles of the two copies of every experiment run by a comparison
+const (
+	roleCanary   = "canary"
+	roleBaseline = "baseline"
+)
+
+// comparing reports whether the experiments also run against a baseline ReplicaSet
+func (c *Config) comparing() bool {
+	return c.BaselineReplicaSetValue != ""
+}
+
+// comparisonSpecs returns a canary and a baseline copy of every experiment, named after the
+// experiment and the role, e.g. pod-kill-canary, or after the role alone for
+// chaosExperimentCRD
+func comparisonSpecs(specs []ExperimentConfig) []ExperimentConfig {
+	copies := make([]ExperimentConfig, 0, 2*len(specs))
+	for _, spec := range specs {
+		for _, role := range []string{roleCanary, roleBaseline} {
+			name := role
+			if spec.Name != "" {
+				name = spec.Name + "-" + role
+			}
+			copies = append(copies, ExperimentConfig{Name: name, ChaosExperimentCRD: spec.ChaosExperimentCRD, role: role})
+		}
+	}
+	return copies
+}
+
+// comparison is the outcome of running the experiments against the canary and the baseline.
+// It is part of the measurement value, so conditions can compare both sides further.
+type comparison struct {
+	Rule     string         `json:"rule"`
+	Canary   comparisonSide `json:"canary"`
+	Baseline comparisonSide `json:"baseline"`
+	// Passed reports whether the canary degraded no worse than the baseline
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// comparisonSide combines the results of the experiments of one role
+type comparisonSide struct {
+	Success bool `json:"success"`
+	chaos.Stats
+}
+
+// aggregateComparison decides the phase of a comparison once every experiment finished, as
+// the outcomes of the canary and the baseline are only meaningful together. An experiment
+// that could not run against either side makes the measurement an error.
+func aggregateComparison(states []experimentState, config *Config) (v1alpha1.AnalysisPhase, bool) {
+	for _, state := range states {
+		if !state.finished() {
+			return "", false
+		}
+	}
+	for _, state := range states {
+		if state.Phase != v1alpha1.AnalysisPhaseSuccessful && state.Phase != v1alpha1.AnalysisPhaseFailed {
+			return v1alpha1.AnalysisPhaseError, true
+		}
+	}
+	if compare(states, config).Passed {
+		return v1alpha1.AnalysisPhaseSuccessful, true
+	}
+	return v1alpha1.AnalysisPhaseFailed, true
+}
+
+// compare applies the comparison rule to the finished experiments. A side succeeds when
+// every one of its experiments succeeded; statistics are combined as in newMeasurementResult.
+func compare(states []experimentState, config *Config) comparison {
+	result := comparison{
+		Rule:     config.Comparison,
+		Canary:   comparisonSide{Success: true},
+		Baseline: comparisonSide{Success: true},
+	}
+	for _, state := range states {
+		side := &result.Canary
+		if state.Role == roleBaseline {
+			side = &result.Baseline
+		}
+		side.Success = side.Success && state.Phase == v1alpha1.AnalysisPhaseSuccessful
+		if state.Stats != nil {
+			side.Stats.Add(*state.Stats)
+		}
+	}
+
+	switch {
+	case !result.Canary.Success && result.Baseline.Success:
+		result.Message = "chaos experiments failed against the canary but succeeded against the baseline"
+	case !result.Canary.Success:
+		result.Passed = true
+		result.Message = "chaos experiments failed against both the canary and the baseline"
+	case result.Rule != ComparisonRecovery || !result.Baseline.Success:
+		result.Passed = true
+		result.Message = "chaos experiments succeeded against the canary"
+	default:
+		tolerance, _ := time.ParseDuration(config.ComparisonTolerance)
+		result.Passed = result.Canary.RecoverySeconds <= result.Baseline.RecoverySeconds+tolerance.Seconds()
+		result.Message = fmt.Sprintf("canary recovered in %.1fs, baseline in %.1fs", result.Canary.RecoverySeconds, result.Baseline.RecoverySeconds)
+		if !result.Passed {
+			result.Message += fmt.Sprintf(", more than the tolerated %s slower", tolerance)
+		}
+	}
+	return result
+}
@@ -0,0 +1,149 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCompare(t *testing.T) {
+	side := func(role string, phase v1alpha1.AnalysisPhase, recoverySeconds float64) experimentState {
+		return experimentState{Role: role, Phase: phase, Stats: &chaos.Stats{RecoverySeconds: recoverySeconds}}
+	}
+
+	tests := []struct {
+		name            string
+		rule            string
+		tolerance       string
+		states          []experimentState
+		expectedPassed  bool
+		expectedMessage string
+	}{
+		{
+			name:            "Canary fails, baseline succeeds",
+			rule:            ComparisonSuccess,
+			states:          []experimentState{side(roleCanary, v1alpha1.AnalysisPhaseFailed, 0), side(roleBaseline, v1alpha1.AnalysisPhaseSuccessful, 0)},
+			expectedPassed:  false,
+			expectedMessage: "chaos experiments failed against the canary but succeeded against the baseline",
+		},
+		{
+			name:            "Both fail",
+			rule:            ComparisonRecovery,
+			states:          []experimentState{side(roleCanary, v1alpha1.AnalysisPhaseFailed, 0), side(roleBaseline, v1alpha1.AnalysisPhaseFailed, 0)},
+			expectedPassed:  true,
+			expectedMessage: "chaos experiments failed against both the canary and the baseline",
+		},
+		{
+			name:            "Success ignores recovery",
+			rule:            ComparisonSuccess,
+			states:          []experimentState{side(roleCanary, v1alpha1.AnalysisPhaseSuccessful, 30), side(roleBaseline, v1alpha1.AnalysisPhaseSuccessful, 5)},
+			expectedPassed:  true,
+			expectedMessage: "chaos experiments succeeded against the canary",
+		},
+		{
+			name:            "Canary recovers as fast",
+			rule:            ComparisonRecovery,
+			states:          []experimentState{side(roleCanary, v1alpha1.AnalysisPhaseSuccessful, 4), side(roleBaseline, v1alpha1.AnalysisPhaseSuccessful, 5)},
+			expectedPassed:  true,
+			expectedMessage: "canary recovered in 4.0s, baseline in 5.0s",
+		},
+		{
+			name:            "Canary recovers slower",
+			rule:            ComparisonRecovery,
+			states:          []experimentState{side(roleCanary, v1alpha1.AnalysisPhaseSuccessful, 12), side(roleBaseline, v1alpha1.AnalysisPhaseSuccessful, 5)},
+			expectedPassed:  false,
+			expectedMessage: "canary recovered in 12.0s, baseline in 5.0s, more than the tolerated 0s slower",
+		},
+		{
+			name:            "Canary recovers slower within tolerance",
+			rule:            ComparisonRecovery,
+			tolerance:       "10s",
+			states:          []experimentState{side(roleCanary, v1alpha1.AnalysisPhaseSuccessful, 12), side(roleBaseline, v1alpha1.AnalysisPhaseSuccessful, 5)},
+			expectedPassed:  true,
+			expectedMessage: "canary recovered in 12.0s, baseline in 5.0s",
+		},
+		{
+			name: "Slowest experiment of each side",
+			rule: ComparisonRecovery,
+			states: []experimentState{
+				side(roleCanary, v1alpha1.AnalysisPhaseSuccessful, 2),
+				side(roleBaseline, v1alpha1.AnalysisPhaseSuccessful, 3),
+				side(roleCanary, v1alpha1.AnalysisPhaseSuccessful, 8),
+				side(roleBaseline, v1alpha1.AnalysisPhaseSuccessful, 1),
+			},
+			expectedPassed:  false,
+			expectedMessage: "canary recovered in 8.0s, baseline in 3.0s, more than the tolerated 0s slower",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := compare(tt.states, &Config{Comparison: tt.rule, ComparisonTolerance: tt.tolerance})
+			if result.Passed != tt.expectedPassed {
+				t.Errorf("Expected passed %t, got %t", tt.expectedPassed, result.Passed)
+			}
+			if result.Message != tt.expectedMessage {
+				t.Errorf("Expected message '%s', got '%s'", tt.expectedMessage, result.Message)
+			}
+		})
+	}
+}
+
+func TestRunComparesWithBaseline(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	metric := newTestMetric(t, Config{
+		ChaosExperimentCRD:      testPodChaos,
+		TargetReplicaSetLabel:   "rollouts-pod-template-hash",
+		TargetReplicaSetValue:   "canary123",
+		BaselineReplicaSetValue: "stable456",
+		Comparison:              ComparisonSuccess,
+		CleanupOnFinish:         true,
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+	ctx := context.Background()
+
+	measurement := plugin.Run(analysisRun, metric)
+	if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+	}
+	states := experimentStates(t, measurement)
+	for name, expectedValue := range map[string]string{"canary": "canary123", "baseline": "stable456"} {
+		experiment, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Get(ctx, states[name].Experiment, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected a %s experiment, got %v", name, err)
+		}
+		value, _, _ := unstructured.NestedString(experiment.Object, "spec", "selector", "labelSelectors", "rollouts-pod-template-hash")
+		if value != expectedValue {
+			t.Errorf("Expected the %s experiment to target %s, got '%s'", name, expectedValue, value)
+		}
+	}
+
+	// The canary fails where the baseline survives the same fault
+	waitForWatch(t, dynamicClient, 1)
+	finishExperiment(t, dynamicClient, states["baseline"].Experiment)
+	experiment, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Get(ctx, states["canary"].Experiment, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get experiment: %v", err)
+	}
+	unstructured.SetNestedField(experiment.Object, "Failed", "status", "experiment", "phase")
+	if _, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Update(ctx, experiment, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update experiment: %v", err)
+	}
+
+	measurement = resumeUntilCompleted(plugin, analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseFailed {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseFailed, measurement.Phase, measurement.Message)
+	}
+	var result measurementResult
+	if err := json.Unmarshal([]byte(measurement.Value), &result); err != nil {
+		t.Fatalf("Failed to decode measurement value: %v", err)
+	}
+	if result.Comparison == nil || result.Comparison.Passed || result.Comparison.Canary.Success || !result.Comparison.Baseline.Success {
+		t.Errorf("Expected the comparison to fail on the canary only, got %+v", result.Comparison)
+	}
+}
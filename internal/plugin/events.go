@@ -0,0 +1,123 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
+	log "github.com/sirupsen/logrus"
+)
+
+// Event policies decide which objects the lifecycle Events of the experiments are recorded on
+const (
+	// EventsAnalysisRun records Events on the AnalysisRun
+	EventsAnalysisRun = "analysisRun"
+	// EventsRollout records Events on the AnalysisRun and on the Rollout that owns it
+	EventsRollout = "rollout"
+	// EventsNone records no Events
+	EventsNone = "none"
+)
+
+// Reasons of the Events recorded over the lifecycle of an experiment
+const (
+	eventReasonPinned       = "ChaosTargetsPinned"
+	eventReasonCreated      = "ChaosExperimentCreated"
+	eventReasonInjected     = "ChaosInjected"
+	eventReasonRecovered    = "ChaosRecovered"
+	eventReasonSucceeded    = "ChaosExperimentSucceeded"
+	eventReasonFailed       = "ChaosExperimentFailed"
+	eventReasonTimedOut     = "ChaosExperimentTimedOut"
+	eventReasonCleanedUp    = "ChaosExperimentCleanedUp"
+	eventReasonCleanupError = "ChaosExperimentCleanupFailed"
+	eventReasonTerminated   = "ChaosExperimentTerminated"
+)
+
+// eventRecorder records the lifecycle Events of the experiments of one AnalysisRun. Failing to
+// record an Event is logged and never affects the measurement.
+type eventRecorder struct {
+	client  *chaos.Client
+	logger  log.Entry
+	objects []chaos.EventObject
+}
+
+// eventRecorder returns the recorder for the experiments of analysisRun. config may be nil
+// when the metric configuration cannot be parsed, in which case Events go to the AnalysisRun.
+func (r *RpcPlugin) eventRecorder(chaosClient *chaos.Client, analysisRun *v1alpha1.AnalysisRun, config *Config) *eventRecorder {
+	recorder := &eventRecorder{client: chaosClient, logger: r.LogCtx}
+	policy := EventsAnalysisRun
+	if config != nil {
+		policy = config.Events
+	}
+	if analysisRun == nil || policy == EventsNone {
+		return recorder
+	}
+
+	recorder.objects = append(recorder.objects, chaos.EventObject{
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       "AnalysisRun",
+		Namespace:  analysisRun.Namespace,
+		Name:       analysisRun.Name,
+		UID:        analysisRun.UID,
+	})
+	if policy == EventsRollout {
+		for _, owner := range analysisRun.OwnerReferences {
+			if owner.Kind != "Rollout" {
+				continue
+			}
+			recorder.objects = append(recorder.objects, chaos.EventObject{
+				APIVersion: owner.APIVersion,
+				Kind:       owner.Kind,
+				Namespace:  analysisRun.Namespace,
+				Name:       owner.Name,
+				UID:        owner.UID,
+			})
+		}
+	}
+	return recorder
+}
+
+// record records an Event on every object of the recorder
+func (e *eventRecorder) record(ctx context.Context, eventType, reason, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	for _, object := range e.objects {
+		if err := e.client.RecordEvent(ctx, object, eventType, reason, message); err != nil {
+			e.logger.Warnf("Failed to record event: %v", err)
+		}
+	}
+}
+
+// recordInjected records that the experiment injected its fault the first time its stats show
+// injected targets, remembering on the state that it did
+func (e *eventRecorder) recordInjected(ctx context.Context, ref chaos.ExperimentRef, state *experimentState, stats chaos.Stats) {
+	if state.InjectedRecorded || stats.InjectedCount == 0 {
+		return
+	}
+	state.InjectedRecorded = true
+	e.record(ctx, chaos.EventTypeNormal, eventReasonInjected, "Chaos experiment %s injected the fault into %d of %d targets", ref, stats.InjectedCount, stats.TargetCount)
+}
+
+// recordTargets records how many targets the finished experiment recovered, and injected
+// unless that was recorded while it ran
+func (e *eventRecorder) recordTargets(ctx context.Context, ref chaos.ExperimentRef, state *experimentState) {
+	stats := state.Stats
+	if stats == nil || stats.InjectedCount == 0 {
+		return
+	}
+	e.recordInjected(ctx, ref, state, *stats)
+	if stats.RecoveredCount > 0 {
+		e.record(ctx, chaos.EventTypeNormal, eventReasonRecovered, "Chaos experiment %s recovered %d of %d injected targets", ref, stats.RecoveredCount, stats.InjectedCount)
+	}
+}
+
+// recordStop records the outcome of stopping or cleaning up an experiment, see applyTerminatePolicy
+func (e *eventRecorder) recordStop(ctx context.Context, ref chaos.ExperimentRef, action string) {
+	switch action {
+	case "deleted":
+		e.record(ctx, chaos.EventTypeNormal, eventReasonCleanedUp, "Deleted chaos experiment %s", ref)
+	case "paused":
+		e.record(ctx, chaos.EventTypeNormal, eventReasonCleanedUp, "Paused chaos experiment %s", ref)
+	case "deleteFailed", "pauseFailed":
+		e.record(ctx, chaos.EventTypeWarning, eventReasonCleanupError, "Failed to stop chaos experiment %s, the fault may still be active", ref)
+	}
+}
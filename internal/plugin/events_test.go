@@ -0,0 +1,276 @@
+package plugin
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// recordedEvents returns the reasons of the Events created through the fake client, by the
+// kind of object they were recorded on
+func recordedEvents(dynamicClient *fake.FakeDynamicClient) map[string][]string {
+	reasons := make(map[string][]string)
+	for _, action := range dynamicClient.Actions() {
+		create, ok := action.(k8stesting.CreateActionImpl)
+		if !ok || action.GetResource().Resource != "events" {
+			continue
+		}
+		event := create.GetObject().(*unstructured.Unstructured)
+		kind, _, _ := unstructured.NestedString(event.Object, "involvedObject", "kind")
+		reason, _, _ := unstructured.NestedString(event.Object, "reason")
+		reasons[kind] = append(reasons[kind], reason)
+	}
+	return reasons
+}
+
+func TestRunRecordsEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		events   string
+		expected map[string][]string
+	}{
+		{
+			name:   "analysisRun",
+			events: EventsAnalysisRun,
+			expected: map[string][]string{
+				"AnalysisRun": {eventReasonCreated, eventReasonSucceeded, eventReasonCleanedUp},
+			},
+		},
+		{
+			name:   "rollout",
+			events: EventsRollout,
+			expected: map[string][]string{
+				"AnalysisRun": {eventReasonCreated, eventReasonSucceeded, eventReasonCleanedUp},
+				"Rollout":     {eventReasonCreated, eventReasonSucceeded, eventReasonCleanedUp},
+			},
+		},
+		{
+			name:     "none",
+			events:   EventsNone,
+			expected: map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin, dynamicClient := newTestPlugin()
+			metric := newTestMetric(t, Config{
+				ChaosExperimentCRD:    testPodChaos,
+				TargetReplicaSetLabel: "rollouts-pod-template-hash",
+				TargetReplicaSetValue: "abc123",
+				CleanupOnFinish:       true,
+				Events:                tt.events,
+			})
+			analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{
+				Name:      "analysis",
+				Namespace: "default",
+				UID:       "run-uid",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Name: "payments", UID: "rollout-uid"},
+				},
+			}}
+
+			measurement := plugin.Run(analysisRun, metric)
+			waitForWatch(t, dynamicClient, 1)
+			finishExperiment(t, dynamicClient, measurement.Metadata["experimentName"])
+			measurement = resumeUntilCompleted(plugin, analysisRun, metric, measurement)
+			if measurement.Phase != v1alpha1.AnalysisPhaseSuccessful {
+				t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+			}
+
+			recorded := recordedEvents(dynamicClient)
+			if len(recorded) != len(tt.expected) {
+				t.Errorf("Expected events on %d objects, got %v", len(tt.expected), recorded)
+			}
+			for kind, reasons := range tt.expected {
+				if strings.Join(recorded[kind], ",") != strings.Join(reasons, ",") {
+					t.Errorf("Expected events %v on the %s, got %v", reasons, kind, recorded[kind])
+				}
+			}
+		})
+	}
+}
+
+// setContainerRecords updates the Chaos Mesh 2.x status of the experiment with one target in
+// the given phase
+func setContainerRecords(t *testing.T, dynamicClient *fake.FakeDynamicClient, name, desiredPhase, phase string) {
+	ctx := context.Background()
+	experiment, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get experiment %s: %v", name, err)
+	}
+	unstructured.SetNestedMap(experiment.Object, map[string]interface{}{
+		"desiredPhase": desiredPhase,
+		"containerRecords": []interface{}{
+			map[string]interface{}{"id": "default/payments-abc123-a", "phase": phase, "injectedCount": int64(1)},
+		},
+	}, "status", "experiment")
+	if _, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Update(ctx, experiment, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update experiment %s: %v", name, err)
+	}
+}
+
+func TestRunRecordsInjectionOnce(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	metric := newTestMetric(t, Config{
+		ChaosExperimentCRD:    testPodFailure,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+
+	measurement := plugin.Run(analysisRun, metric)
+	waitForWatch(t, dynamicClient, 1)
+	name := measurement.Metadata["experimentName"]
+
+	// The fault lands while the experiment is still running
+	setContainerRecords(t, dynamicClient, name, chaos.DesiredPhaseRun, "Injected")
+	for i := 0; i < 2; i++ {
+		measurement = plugin.Resume(analysisRun, metric, measurement)
+	}
+	if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+	}
+	reasons := recordedEvents(dynamicClient)["AnalysisRun"]
+	if strings.Join(reasons, ",") != eventReasonCreated+","+eventReasonInjected {
+		t.Errorf("Expected the injection to be recorded once while running, got %v", reasons)
+	}
+
+	setContainerRecords(t, dynamicClient, name, chaos.DesiredPhaseStop, "NotInjected")
+	measurement = resumeUntilCompleted(plugin, analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseSuccessful {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	}
+	reasons = recordedEvents(dynamicClient)["AnalysisRun"]
+	expected := []string{eventReasonCreated, eventReasonInjected, eventReasonRecovered, eventReasonSucceeded, eventReasonCleanedUp}
+	if strings.Join(reasons, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected events %v, got %v", expected, reasons)
+	}
+}
+
+func TestTerminateRecordsEvent(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	metric := newTestMetric(t, Config{
+		ChaosExperimentCRD:    testPodChaos,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+		TerminatePolicy:       TerminatePolicyPause,
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+
+	measurement := plugin.Run(analysisRun, metric)
+	plugin.Terminate(analysisRun, metric, measurement)
+
+	reasons := recordedEvents(dynamicClient)["AnalysisRun"]
+	if strings.Join(reasons, ",") != eventReasonCreated+","+eventReasonTerminated {
+		t.Errorf("Expected the experiment to be created and terminated, got %v", reasons)
+	}
+}
+
+func TestRunFailureSummarisesControllerEvents(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	metric := newTestMetric(t, Config{
+		ChaosExperimentCRD:    testPodChaos,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+	ctx := context.Background()
+
+	measurement := plugin.Run(analysisRun, metric)
+	name := measurement.Metadata["experimentName"]
+	waitForWatch(t, dynamicClient, 1)
+
+	// The chaos-controller-manager explains the failure in an Event on the experiment
+	event := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion":     "v1",
+		"kind":           "Event",
+		"metadata":       map[string]interface{}{"name": name + ".1", "namespace": "default"},
+		"involvedObject": map[string]interface{}{"kind": "PodChaos", "name": name, "namespace": "default"},
+		"type":           "Warning",
+		"reason":         "Failed",
+		"message":        "Failed to apply chaos: no pod is selected",
+		"count":          int64(2),
+	}}
+	if _, err := dynamicClient.Resource(eventGVR).Namespace("default").Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	experiment, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get experiment: %v", err)
+	}
+	unstructured.SetNestedField(experiment.Object, "Failed", "status", "experiment", "phase")
+	if _, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Update(ctx, experiment, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update experiment: %v", err)
+	}
+
+	measurement = resumeUntilCompleted(plugin, analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseFailed {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseFailed, measurement.Phase, measurement.Message)
+	}
+	expected := "controller reported: Failed: Failed to apply chaos: no pod is selected (2 times)"
+	if measurement.Message != expected {
+		t.Errorf("Expected message '%s', got '%s'", expected, measurement.Message)
+	}
+}
+
+func TestRunFailsOnSelectionWarningWhileWatching(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	metric := newTestMetric(t, Config{
+		ChaosExperimentCRD:    testPodFailure,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+	ctx := context.Background()
+
+	measurement := plugin.Run(analysisRun, metric)
+	name := measurement.Metadata["experimentName"]
+	waitForWatch(t, dynamicClient, 1)
+
+	// Chaos Mesh reports the targets as not selected yet...
+	experiment, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get experiment: %v", err)
+	}
+	unstructured.SetNestedSlice(experiment.Object, []interface{}{
+		map[string]interface{}{"type": chaos.ConditionSelected, "status": "False"},
+	}, "status", "conditions")
+	unstructured.SetNestedField(experiment.Object, chaos.DesiredPhaseRun, "status", "experiment", "desiredPhase")
+	if _, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Update(ctx, experiment, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update experiment: %v", err)
+	}
+	measurement = plugin.Resume(analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+	}
+
+	// ...then only records an Event once selecting them failed, leaving the experiment unchanged
+	event := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion":     "v1",
+		"kind":           "Event",
+		"metadata":       map[string]interface{}{"name": name + ".1", "namespace": "default"},
+		"involvedObject": map[string]interface{}{"kind": "PodChaos", "name": name, "namespace": "default"},
+		"type":           "Warning",
+		"reason":         "Failed",
+		"message":        "Failed to select targets: no pod is selected",
+	}}
+	if _, err := dynamicClient.Resource(eventGVR).Namespace("default").Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	measurement = plugin.Resume(analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseFailed {
+		t.Fatalf("Expected phase %s before the deadline, got %s (%s)", v1alpha1.AnalysisPhaseFailed, measurement.Phase, measurement.Message)
+	}
+	if !strings.Contains(measurement.Message, "Failed to select targets") {
+		t.Errorf("Expected the message to report the selection failure, got '%s'", measurement.Message)
+	}
+}
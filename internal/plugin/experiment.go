@@ -0,0 +1,114 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// Measurement metadata keys holding the coordinates of the experiment behind a measurement
+const (
+	metadataExperimentName       = "experimentName"
+	metadataExperimentNamespace  = "experimentNamespace"
+	metadataExperimentKind       = "experimentKind"
+	metadataExperimentAPIVersion = "experimentApiVersion"
+	metadataExperimentUID        = "experimentUID"
+	metadataDeadline             = "deadline"
+)
+
+var errNoExperiment = fmt.Errorf("measurement has no experiment to resume")
+
+// trackingFor returns the identity stamped on experiments created for the next measurement of metric
+func trackingFor(analysisRun *v1alpha1.AnalysisRun, metric v1alpha1.Metric, deadline time.Time) chaos.Tracking {
+	return chaos.Tracking{
+		AnalysisRunNamespace: analysisRun.Namespace,
+		AnalysisRunName:      analysisRun.Name,
+		AnalysisRunUID:       string(analysisRun.UID),
+		MetricName:           metric.Name,
+		MeasurementIndex:     measurementCount(analysisRun, metric.Name),
+		Deadline:             deadline,
+	}
+}
+
+// measurementCount returns how many measurements of the metric the AnalysisRun has completed.
+// The counters are used rather than len(Measurements) since the controller trims that list.
+func measurementCount(analysisRun *v1alpha1.AnalysisRun, metricName string) int {
+	for _, result := range analysisRun.Status.MetricResults {
+		if result.Name == metricName {
+			return int(result.Count + result.Error)
+		}
+	}
+	return 0
+}
+
+// setExperimentRef records the coordinates of an experiment on the measurement so a later
+// Resume or Terminate, possibly in another plugin process, can re-attach to it
+func setExperimentRef(measurement *v1alpha1.Measurement, ref chaos.ExperimentRef) {
+	if measurement.Metadata == nil {
+		measurement.Metadata = make(map[string]string)
+	}
+	measurement.Metadata[metadataExperimentName] = ref.Name
+	measurement.Metadata[metadataExperimentNamespace] = ref.Namespace
+	measurement.Metadata[metadataExperimentKind] = ref.Kind
+	measurement.Metadata[metadataExperimentAPIVersion] = ref.APIVersion
+	measurement.Metadata[metadataExperimentUID] = string(ref.UID)
+}
+
+// setDeadline records the time after which the measurement times out
+func setDeadline(measurement *v1alpha1.Measurement, deadline time.Time) {
+	if measurement.Metadata == nil {
+		measurement.Metadata = make(map[string]string)
+	}
+	measurement.Metadata[metadataDeadline] = deadline.UTC().Format(time.RFC3339)
+}
+
+// measurementDeadline returns the deadline recorded on the measurement, or the zero time
+func measurementDeadline(measurement v1alpha1.Measurement) time.Time {
+	deadline, _ := time.Parse(time.RFC3339, measurement.Metadata[metadataDeadline])
+	return deadline
+}
+
+// experimentRefFromMetadata reads the experiment coordinates back from the measurement.
+// Measurements written before the UID was recorded yield a reference without one.
+func experimentRefFromMetadata(measurement v1alpha1.Measurement) (chaos.ExperimentRef, bool) {
+	name, exists := measurement.Metadata[metadataExperimentName]
+	if !exists || name == "" {
+		return chaos.ExperimentRef{}, false
+	}
+	return chaos.ExperimentRef{
+		APIVersion: measurement.Metadata[metadataExperimentAPIVersion],
+		Kind:       measurement.Metadata[metadataExperimentKind],
+		Namespace:  measurement.Metadata[metadataExperimentNamespace],
+		Name:       name,
+		UID:        k8stypes.UID(measurement.Metadata[metadataExperimentUID]),
+	}, true
+}
+
+// locateExperiment finds the experiment behind a measurement state. It uses the recorded
+// coordinates when present and otherwise searches for an experiment carrying the tracking
+// labels of this AnalysisRun, metric and experiment name.
+func (r *RpcPlugin) locateExperiment(ctx context.Context, chaosClient *chaos.Client, analysisRun *v1alpha1.AnalysisRun, metric v1alpha1.Metric, specs []ExperimentConfig, state experimentState) (*unstructured.Unstructured, error) {
+	if ref, ok := state.ref(); ok {
+		return chaosClient.GetExperiment(ctx, ref)
+	}
+
+	for _, spec := range specs {
+		if spec.Name != state.Name {
+			continue
+		}
+		template, err := chaos.ParseExperiment(spec.ChaosExperimentCRD)
+		if err != nil {
+			return nil, err
+		}
+		r.LogCtx.Infof("Measurement has no experiment coordinates, searching by tracking labels")
+		tracking := trackingFor(analysisRun, metric, time.Time{})
+		tracking.Step = spec.Name
+		return chaosClient.FindExperiment(ctx, template.GetAPIVersion(), template.GetKind(), template.GetNamespace(), tracking)
+	}
+	return nil, errNoExperiment
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
@@ -14,16 +15,65 @@ import (
 	metricutil "github.com/argoproj/argo-rollouts/utils/metric"
 	timeutil "github.com/argoproj/argo-rollouts/utils/time"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
 	PluginName = "argo-rollouts-chaos-mesh-plugin"
 	DefaultTimeout = 5 * time.Minute
+	// DefaultResumeInterval is how long the controller waits before polling a running experiment again
+	DefaultResumeInterval = 10 * time.Second
+)
+
+// Terminate policies decide what happens to a running experiment when its measurement is terminated
+const (
+	// TerminatePolicyDelete deletes the experiment, recovering the fault and removing its records
+	TerminatePolicyDelete = "delete"
+	// TerminatePolicyPause pauses the experiment, recovering the fault but keeping the object and its records
+	TerminatePolicyPause = "pause"
+	// TerminatePolicyKeep leaves the experiment untouched so the fault stays active
+	TerminatePolicyKeep = "keep"
+)
+
+// Execution modes decide when the experiments of a metric with an experiments list start
+const (
+	// ExecutionModeParallel starts every experiment at once
+	ExecutionModeParallel = "parallel"
+	// ExecutionModeSequential starts each experiment once the previous one has finished
+	ExecutionModeSequential = "sequential"
+)
+
+// Aggregations decide the measurement outcome from the results of its experiments
+const (
+	// AggregationAll requires every experiment to succeed
+	AggregationAll = "all"
+	// AggregationAny requires at least one experiment to succeed
+	AggregationAny = "any"
 )
 
 // RpcPlugin implements the Argo Rollouts metric provider plugin interface
 type RpcPlugin struct {
 	LogCtx log.Entry
+
+	// ClientOptions tunes the Kubernetes client created by InitPlugin
+	ClientOptions chaos.ClientOptions
+
+	// OrphanSweepInterval is how often orphaned cross-namespace experiments are deleted
+	// (default: DefaultOrphanSweepInterval)
+	OrphanSweepInterval time.Duration
+
+	// client is shared by all RPC calls once InitPlugin has created it
+	client   *chaos.Client
+	clientMu sync.RWMutex
+
+	// runs tracks the measurements this process is watching
+	runs runRegistry
+
+	// sweep deletes orphaned cross-namespace experiments once InitPlugin has run
+	sweep   *orphanSweep
+	sweepMu sync.Mutex
 }
 
 // Config represents the plugin configuration
@@ -39,21 +89,130 @@ type Config struct {
 	
 	// TargetReplicaSetValue is the label value for the target ReplicaSet
 	TargetReplicaSetValue string `json:"targetReplicaSetValue"`
+
+	// SelectorMergeStrategy decides how the target label combines with the labelSelectors of
+	// the experiment template: merge, replace or failOnConflict (default: merge)
+	SelectorMergeStrategy string `json:"selectorMergeStrategy,omitempty"`
+
+	// NetworkTarget decides which pods of a NetworkChaos the target label selects: the source
+	// pods of spec.selector, the pods of spec.target or both (default: source)
+	NetworkTarget string `json:"networkTarget,omitempty"`
+
+	// BaselineReplicaSetValue is the label value of the ReplicaSet the target is compared
+	// against, usually the stable one. Every experiment then also runs against it and the
+	// measurement passes only if the target degrades no worse, see Comparison.
+	BaselineReplicaSetValue string `json:"baselineReplicaSetValue,omitempty"`
+
+	// Comparison is the rule deciding whether the target degraded no worse than the baseline:
+	// success or recovery (default: recovery)
+	Comparison string `json:"comparison,omitempty"`
+
+	// ComparisonTolerance is how much longer than the baseline the target may take to recover
+	// under the recovery comparison (default: 0s)
+	ComparisonTolerance string `json:"comparisonTolerance,omitempty"`
+
+	// PinTargetPods lists the pods of the target ReplicaSet when an experiment starts and pins
+	// the experiment to them, so pods created later are never hit
+	PinTargetPods bool `json:"pinTargetPods,omitempty"`
+
+	// ExperimentNamespace is the namespace of experiments whose YAML sets none (default: the
+	// namespace of the AnalysisRun)
+	ExperimentNamespace string `json:"experimentNamespace,omitempty"`
+
+	// TargetNamespace is set as the selector namespaces of experiments whose YAML selects none
+	// (default: the namespace of the AnalysisRun)
+	TargetNamespace string `json:"targetNamespace,omitempty"`
 	
 	// Timeout for the chaos experiment (default: 5 minutes)
 	Timeout string `json:"timeout,omitempty"`
 	
 	// CleanupOnFinish determines if the experiment should be deleted after completion
 	CleanupOnFinish bool `json:"cleanupOnFinish,omitempty"`
+
+	// TerminatePolicy is what to do with a running experiment when the measurement is
+	// terminated: delete, pause or keep (default: delete)
+	TerminatePolicy string `json:"terminatePolicy,omitempty"`
+
+	// Experiments runs several experiments for the metric instead of ChaosExperimentCRD
+	Experiments []ExperimentConfig `json:"experiments,omitempty"`
+
+	// ExecutionMode starts the experiments all at once or one after another: parallel or
+	// sequential (default: parallel)
+	ExecutionMode string `json:"executionMode,omitempty"`
+
+	// Aggregation decides whether every experiment or at least one must succeed: all or any
+	// (default: all)
+	Aggregation string `json:"aggregation,omitempty"`
+
+	// Events records the lifecycle of the experiments as Kubernetes Events on the AnalysisRun
+	// (analysisRun), also on the Rollout that owns it (rollout), or not at all (none)
+	// (default: analysisRun)
+	Events string `json:"events,omitempty"`
+}
+
+// ExperimentConfig is one entry of the experiments list
+type ExperimentConfig struct {
+	// Name identifies the experiment in the measurement metadata
+	Name string `json:"name"`
+
+	// ChaosExperimentCRD is the YAML definition of the Chaos Mesh experiment
+	ChaosExperimentCRD string `json:"chaosExperimentCRD"`
+
+	// role tells the canary and baseline copies of a comparison apart, see comparisonSpecs
+	role string
+}
+
+// multiExperiment reports whether the metric uses the experiments list
+func (c *Config) multiExperiment() bool {
+	return len(c.Experiments) > 0
+}
+
+// experimentSpecs returns the experiments to run, a single unnamed one for ChaosExperimentCRD,
+// each run against both the canary and the baseline when comparing
+func (c *Config) experimentSpecs() []ExperimentConfig {
+	specs := []ExperimentConfig{{ChaosExperimentCRD: c.ChaosExperimentCRD}}
+	if c.multiExperiment() {
+		specs = c.Experiments
+	}
+	if c.comparing() {
+		return comparisonSpecs(specs)
+	}
+	return specs
 }
 
-// InitPlugin initializes the plugin
+// InitPlugin initializes the plugin and the Kubernetes client shared by all RPC calls
 func (r *RpcPlugin) InitPlugin() types.RpcError {
 	r.LogCtx.Info("Initializing Chaos Mesh plugin")
+
+	chaosClient, err := chaos.NewClient(r.LogCtx, r.ClientOptions)
+	if err != nil {
+		r.LogCtx.Errorf("Failed to create Chaos Mesh client: %v", err)
+		return types.RpcError{ErrorString: fmt.Sprintf("failed to create Chaos Mesh client: %v", err)}
+	}
+	r.SetClient(chaosClient)
+	r.startOrphanSweep(chaosClient)
+
 	return types.RpcError{}
 }
 
-// Run executes the chaos experiment and returns the measurement
+// SetClient replaces the Chaos Mesh client shared by all RPC calls
+func (r *RpcPlugin) SetClient(chaosClient *chaos.Client) {
+	r.clientMu.Lock()
+	defer r.clientMu.Unlock()
+	r.client = chaosClient
+}
+
+// chaosClient returns the shared Chaos Mesh client
+func (r *RpcPlugin) chaosClient() (*chaos.Client, error) {
+	r.clientMu.RLock()
+	defer r.clientMu.RUnlock()
+	if r.client == nil {
+		return nil, fmt.Errorf("chaos mesh client is not initialized, InitPlugin must succeed first")
+	}
+	return r.client, nil
+}
+
+// Run creates the chaos experiments and returns a running measurement that Resume completes
 func (r *RpcPlugin) Run(analysisRun *v1alpha1.AnalysisRun, metric v1alpha1.Metric) v1alpha1.Measurement {
 	startTime := timeutil.MetaNow()
 	newMeasurement := v1alpha1.Measurement{
@@ -73,125 +232,176 @@ func (r *RpcPlugin) Run(analysisRun *v1alpha1.AnalysisRun, metric v1alpha1.Metri
 		return metricutil.MarkMeasurementError(newMeasurement, err)
 	}
 
-	// Create Chaos Mesh client
-	chaosClient, err := chaos.NewClient(r.LogCtx)
+	chaosClient, err := r.chaosClient()
 	if err != nil {
-		r.LogCtx.Errorf("Failed to create Chaos Mesh client: %v", err)
+		r.LogCtx.Errorf("Chaos Mesh client unavailable: %v", err)
 		return metricutil.MarkMeasurementError(newMeasurement, err)
 	}
 
-	// Build target selector
-	targetSelector := map[string]string{
-		config.TargetReplicaSetLabel: config.TargetReplicaSetValue,
+	// Create the chaos experiments under a context Terminate can cancel
+	run := r.runs.start(runKeyFor(analysisRun, metric))
+	setDeadline(&newMeasurement, startTime.Add(r.parseTimeout(config)))
+	newMeasurement.Metadata["targetSelector"] = fmt.Sprintf("%s=%s", config.TargetReplicaSetLabel, config.TargetReplicaSetValue)
+	if config.comparing() {
+		newMeasurement.Metadata["baselineSelector"] = fmt.Sprintf("%s=%s", config.TargetReplicaSetLabel, config.BaselineReplicaSetValue)
 	}
 
-	r.LogCtx.Infof("Creating chaos experiment with target selector: %v", targetSelector)
+	return r.progress(chaosClient, analysisRun, metric, config, run, newExperimentStates(config), newMeasurement)
+}
 
-	// Create the chaos experiment
-	ctx := context.Background()
-	experiment, err := chaosClient.CreateExperiment(ctx, config.ChaosExperimentCRD, targetSelector)
-	if err != nil {
-		r.LogCtx.Errorf("Failed to create chaos experiment: %v", err)
-		return metricutil.MarkMeasurementError(newMeasurement, err)
+// Resume re-reads the experiment status and finalizes the measurement once the
+// experiments have completed or its deadline has passed
+func (r *RpcPlugin) Resume(analysisRun *v1alpha1.AnalysisRun, metric v1alpha1.Metric, measurement v1alpha1.Measurement) v1alpha1.Measurement {
+	if measurement.Phase.Completed() {
+		return measurement
 	}
 
-	experimentName := experiment.GetName()
-	experimentNamespace := experiment.GetNamespace()
-	experimentKind := experiment.GetKind()
-
-	r.LogCtx.Infof("Created chaos experiment: %s/%s (kind: %s)", experimentNamespace, experimentName, experimentKind)
-
-	// Parse timeout
-	timeout := DefaultTimeout
-	if config.Timeout != "" {
-		if parsedTimeout, err := time.ParseDuration(config.Timeout); err == nil {
-			timeout = parsedTimeout
-		} else {
-			r.LogCtx.Warnf("Invalid timeout format '%s', using default %v", config.Timeout, DefaultTimeout)
-		}
+	config, err := r.parseConfig(metric)
+	if err != nil {
+		r.LogCtx.Errorf("Failed to parse config: %v", err)
+		return metricutil.MarkMeasurementError(measurement, err)
 	}
 
-	// Watch the experiment until completion
-	success, err := chaosClient.WatchExperiment(ctx, experimentNamespace, experimentName, experimentKind, timeout)
+	chaosClient, err := r.chaosClient()
 	if err != nil {
-		r.LogCtx.Errorf("Failed to watch chaos experiment: %v", err)
-		// Try to cleanup the experiment
-		if config.CleanupOnFinish {
-			if cleanupErr := chaosClient.DeleteExperiment(ctx, experimentNamespace, experimentName, experimentKind); cleanupErr != nil {
-				r.LogCtx.Warnf("Failed to cleanup experiment after error: %v", cleanupErr)
-			}
-		}
-		return metricutil.MarkMeasurementError(newMeasurement, err)
+		r.LogCtx.Errorf("Chaos Mesh client unavailable: %v", err)
+		return metricutil.MarkMeasurementError(measurement, err)
 	}
 
-	// Cleanup experiment if requested
-	if config.CleanupOnFinish {
-		if err := chaosClient.DeleteExperiment(ctx, experimentNamespace, experimentName, experimentKind); err != nil {
-			r.LogCtx.Warnf("Failed to cleanup experiment: %v", err)
-		} else {
-			r.LogCtx.Infof("Cleaned up chaos experiment: %s/%s", experimentNamespace, experimentName)
-		}
+	key := runKeyFor(analysisRun, metric)
+	states, err := loadExperimentStates(config, measurement)
+	if err != nil {
+		r.LogCtx.Errorf("Failed to get chaos experiment: %v", err)
+		r.runs.remove(key)
+		return metricutil.MarkMeasurementError(measurement, err)
 	}
 
-	// Set measurement result
-	finishedTime := timeutil.MetaNow()
-	newMeasurement.FinishedAt = &finishedTime
-	newMeasurement.Metadata = map[string]string{
-		"experimentName":      experimentName,
-		"experimentNamespace": experimentNamespace,
-		"experimentKind":      experimentKind,
-		"targetSelector":      fmt.Sprintf("%s=%s", config.TargetReplicaSetLabel, config.TargetReplicaSetValue),
-	}
-
-	if success {
-		r.LogCtx.Infof("Chaos experiment completed successfully")
-		newMeasurement.Phase = v1alpha1.AnalysisPhaseSuccessful
-		newMeasurement.Value = "1"
-	} else {
-		r.LogCtx.Errorf("Chaos experiment failed")
-		newMeasurement.Phase = v1alpha1.AnalysisPhaseFailed
-		newMeasurement.Value = "0"
+	// After a restart the run is registered again so Terminate can cancel it
+	run := r.runs.get(key)
+	if run == nil {
+		run = r.runs.start(key)
 	}
 
-	return newMeasurement
-}
-
-// Resume resumes a paused measurement (not implemented for chaos experiments)
-func (r *RpcPlugin) Resume(analysisRun *v1alpha1.AnalysisRun, metric v1alpha1.Metric, measurement v1alpha1.Measurement) v1alpha1.Measurement {
-	r.LogCtx.Debug("Resume called - not implemented for chaos experiments")
-	return measurement
+	return r.progress(chaosClient, analysisRun, metric, config, run, states, measurement)
 }
 
 // Terminate terminates a running measurement
 func (r *RpcPlugin) Terminate(analysisRun *v1alpha1.AnalysisRun, metric v1alpha1.Metric, measurement v1alpha1.Measurement) v1alpha1.Measurement {
 	r.LogCtx.Info("Terminating chaos experiment measurement")
-	
-	// Try to cleanup the experiment if it exists
 
-	if experimentName, exists := measurement.Metadata["experimentName"]; exists {
-		experimentNamespace := measurement.Metadata["experimentNamespace"]
-		experimentKind := measurement.Metadata["experimentKind"]
-		
-		chaosClient, err := chaos.NewClient(r.LogCtx)
-		if err != nil {
-			r.LogCtx.Errorf("Failed to create Chaos Mesh client during termination: %v", err)
-			return measurement
-		}
+	// Stop the background watch right away, and forget the run once cleanup has run
+	run := r.runs.get(runKeyFor(analysisRun, metric))
+	if run != nil {
+		run.cancel()
+		defer r.runs.finish(run)
+	}
 
-		ctx := context.Background()
-		if err := chaosClient.DeleteExperiment(ctx, experimentNamespace, experimentName, experimentKind); err != nil {
-			r.LogCtx.Warnf("Failed to cleanup experiment during termination: %v", err)
+	// Try to cleanup the experiment if it exists, even if it was created by a previous plugin process
+	config, err := r.parseConfig(metric)
+	if err != nil {
+		r.LogCtx.Warnf("Failed to parse config during termination: %v", err)
+		config = nil
+	}
+
+	chaosClient, err := r.chaosClient()
+	if err != nil {
+		r.LogCtx.Errorf("Chaos Mesh client unavailable during termination: %v", err)
+		return measurement
+	}
+
+	policy := TerminatePolicyDelete
+	var specs []ExperimentConfig
+	if config != nil {
+		policy = config.TerminatePolicy
+		specs = config.experimentSpecs()
+	}
+
+	ctx := context.Background()
+	events := r.eventRecorder(chaosClient, analysisRun, config)
+	var actions, failed []string
+	states, err := loadExperimentStates(config, measurement)
+	if err != nil {
+		r.LogCtx.Infof("No chaos experiment to clean up during termination")
+	}
+	for i := range states {
+		if states[i].Phase != v1alpha1.AnalysisPhaseRunning {
+			continue
+		}
+		action := "none"
+		experiment, err := r.locateExperiment(ctx, chaosClient, analysisRun, metric, specs, states[i])
+		switch {
+		case err == errNoExperiment || errors.IsNotFound(err):
+			r.LogCtx.Infof("No chaos experiment to clean up during termination")
+		case err != nil:
+			r.LogCtx.Warnf("Failed to find experiment during termination: %v", err)
+		default:
+			states[i].setExperiment(experiment)
+			ref := chaos.RefFor(experiment)
+			r.cleanupExperiment(run, ref, func() {
+				action = r.applyTerminatePolicy(ctx, chaosClient, policy, ref)
+				if strings.HasSuffix(action, "Failed") {
+					events.record(ctx, chaos.EventTypeWarning, eventReasonTerminated, "Terminated measurement of metric %s, but failed to stop chaos experiment %s", metric.Name, ref)
+				} else {
+					events.record(ctx, chaos.EventTypeNormal, eventReasonTerminated, "Terminated measurement of metric %s, chaos experiment %s %s", metric.Name, ref, action)
+				}
+			})
+			if strings.HasSuffix(action, "Failed") {
+				failed = append(failed, ref.Namespace+"/"+ref.Name)
+			}
+		}
+		states[i].Phase = phaseStopped
+		states[i].Action = action
+		if states[i].Name == "" {
+			actions = append(actions, action)
 		} else {
-			r.LogCtx.Infof("Cleaned up chaos experiment during termination: %s/%s", experimentNamespace, experimentName)
+			actions = append(actions, states[i].Name+"="+action)
 		}
 	}
+	if len(actions) == 0 {
+		actions = append(actions, "none")
+	}
+	saveExperimentStates(&measurement, states)
+	measurement.Metadata["terminateAction"] = strings.Join(actions, ",")
 
+	// The controller keeps resuming a measurement until it is finished
+	finishedTime := timeutil.MetaNow()
+	measurement.FinishedAt = &finishedTime
+	measurement.ResumeAt = nil
+
+	// Like the job provider of Argo Rollouts, report an error when the fault may still be active
+	if len(failed) > 0 {
+		return metricutil.MarkMeasurementError(measurement, fmt.Errorf("failed to stop chaos experiments %s, the fault may still be active", strings.Join(failed, ", ")))
+	}
+	measurement.Phase = v1alpha1.AnalysisPhaseSuccessful
 	return measurement
 }
 
-// GarbageCollect cleans up old measurements (not implemented)
-func (r *RpcPlugin) GarbageCollect(*v1alpha1.AnalysisRun, v1alpha1.Metric, int) types.RpcError {
-	r.LogCtx.Debug("GarbageCollect called - not implemented")
+// GarbageCollect deletes the experiments created for the metric of this AnalysisRun,
+// keeping only the limit most recent ones. Orphaned cross-namespace experiments are deleted
+// by the orphan sweep instead, see startOrphanSweep.
+func (r *RpcPlugin) GarbageCollect(analysisRun *v1alpha1.AnalysisRun, metric v1alpha1.Metric, limit int) types.RpcError {
+	chaosClient, err := r.chaosClient()
+	if err != nil {
+		r.LogCtx.Errorf("Chaos Mesh client unavailable: %v", err)
+		return types.RpcError{ErrorString: err.Error()}
+	}
+
+	// Every measurement of a metric with an experiments list creates one experiment per entry
+	keep := limit
+	if config, err := r.parseConfig(metric); err == nil {
+		keep = limit * len(config.experimentSpecs())
+	}
+
+	tracking := trackingFor(analysisRun, metric, time.Time{})
+	deleted, err := chaosClient.DeleteOldExperiments(context.Background(), tracking.Selector(), keep)
+	if err != nil {
+		r.LogCtx.Errorf("Failed to garbage collect chaos experiments: %v", err)
+		return types.RpcError{ErrorString: err.Error()}
+	}
+
+	if deleted > 0 {
+		r.LogCtx.Infof("Garbage collected %d chaos experiment(s) for metric %s", deleted, metric.Name)
+	}
 	return types.RpcError{}
 }
 
@@ -213,8 +423,23 @@ func (r *RpcPlugin) GetMetadata(metric v1alpha1.Metric) map[string]string {
 	metadata["pluginName"] = PluginName
 	metadata["targetReplicaSetLabel"] = config.TargetReplicaSetLabel
 	metadata["targetReplicaSetValue"] = config.TargetReplicaSetValue
+	metadata["selectorMergeStrategy"] = config.SelectorMergeStrategy
+	metadata["networkTarget"] = config.NetworkTarget
+	metadata["pinTargetPods"] = fmt.Sprintf("%t", config.PinTargetPods)
+	if config.comparing() {
+		metadata["baselineReplicaSetValue"] = config.BaselineReplicaSetValue
+		metadata["comparison"] = config.Comparison
+		metadata["comparisonTolerance"] = config.ComparisonTolerance
+	}
 	metadata["timeout"] = config.Timeout
 	metadata["cleanupOnFinish"] = fmt.Sprintf("%t", config.CleanupOnFinish)
+	metadata["terminatePolicy"] = config.TerminatePolicy
+	metadata["events"] = config.Events
+	if config.multiExperiment() {
+		metadata["experimentCount"] = fmt.Sprintf("%d", len(config.Experiments))
+		metadata["executionMode"] = config.ExecutionMode
+		metadata["aggregation"] = config.Aggregation
+	}
 	
 	// Extract experiment kind from CRD
 	if strings.Contains(config.ChaosExperimentCRD, "kind:") {
@@ -236,8 +461,15 @@ func (r *RpcPlugin) GetMetadata(metric v1alpha1.Metric) map[string]string {
 // parseConfig parses the plugin configuration from the metric
 func (r *RpcPlugin) parseConfig(metric v1alpha1.Metric) (*Config, error) {
 	config := &Config{
-		CleanupOnFinish: true, // Default to cleanup
-		Timeout:         DefaultTimeout.String(),
+		CleanupOnFinish:       true, // Default to cleanup
+		Timeout:               DefaultTimeout.String(),
+		SelectorMergeStrategy: chaos.MergeStrategyMerge,
+		NetworkTarget:         chaos.NetworkSideSource,
+		Comparison:            ComparisonRecovery,
+		TerminatePolicy:       TerminatePolicyDelete,
+		ExecutionMode:         ExecutionModeParallel,
+		Aggregation:           AggregationAll,
+		Events:                EventsAnalysisRun,
 	}
 
 	// The plugin configuration should be under the plugin name key
@@ -255,8 +487,26 @@ func (r *RpcPlugin) parseConfig(metric v1alpha1.Metric) (*Config, error) {
 
 // validateConfig validates the plugin configuration
 func (r *RpcPlugin) validateConfig(config *Config) error {
-	if config.ChaosExperimentCRD == "" {
-		return fmt.Errorf("chaosExperimentCRD is required")
+	if config.ChaosExperimentCRD == "" && len(config.Experiments) == 0 {
+		return fmt.Errorf("chaosExperimentCRD or experiments is required")
+	}
+
+	if config.ChaosExperimentCRD != "" && len(config.Experiments) > 0 {
+		return fmt.Errorf("chaosExperimentCRD and experiments are mutually exclusive")
+	}
+
+	names := make(map[string]bool)
+	for i, experiment := range config.Experiments {
+		if experiment.Name == "" {
+			return fmt.Errorf("experiments[%d].name is required", i)
+		}
+		if names[experiment.Name] {
+			return fmt.Errorf("duplicate experiment name '%s'", experiment.Name)
+		}
+		names[experiment.Name] = true
+		if experiment.ChaosExperimentCRD == "" {
+			return fmt.Errorf("experiments[%d].chaosExperimentCRD is required", i)
+		}
 	}
 
 	if config.TargetReplicaSetLabel == "" {
@@ -274,5 +524,165 @@ func (r *RpcPlugin) validateConfig(config *Config) error {
 		}
 	}
 
+	namespaces := []struct{ field, value string }{
+		{"experimentNamespace", config.ExperimentNamespace},
+		{"targetNamespace", config.TargetNamespace},
+	}
+	for _, namespace := range namespaces {
+		if namespace.value == "" {
+			continue
+		}
+		if errs := validation.IsDNS1123Label(namespace.value); len(errs) > 0 {
+			return fmt.Errorf("invalid %s '%s': %s", namespace.field, namespace.value, strings.Join(errs, ", "))
+		}
+	}
+
+	switch config.SelectorMergeStrategy {
+	case "", chaos.MergeStrategyMerge, chaos.MergeStrategyReplace, chaos.MergeStrategyFailOnConflict:
+	default:
+		return fmt.Errorf("invalid selectorMergeStrategy '%s': must be one of %s, %s, %s", config.SelectorMergeStrategy, chaos.MergeStrategyMerge, chaos.MergeStrategyReplace, chaos.MergeStrategyFailOnConflict)
+	}
+
+	if config.comparing() {
+		if config.BaselineReplicaSetValue == config.TargetReplicaSetValue {
+			return fmt.Errorf("baselineReplicaSetValue must differ from targetReplicaSetValue")
+		}
+		if config.Aggregation == AggregationAny {
+			return fmt.Errorf("aggregation %s cannot be combined with baselineReplicaSetValue", AggregationAny)
+		}
+	}
+
+	switch config.Comparison {
+	case "", ComparisonSuccess, ComparisonRecovery:
+	default:
+		return fmt.Errorf("invalid comparison '%s': must be one of %s, %s", config.Comparison, ComparisonSuccess, ComparisonRecovery)
+	}
+
+	if config.ComparisonTolerance != "" {
+		tolerance, err := time.ParseDuration(config.ComparisonTolerance)
+		if err != nil {
+			return fmt.Errorf("invalid comparisonTolerance format: %w", err)
+		}
+		if tolerance < 0 {
+			return fmt.Errorf("comparisonTolerance must not be negative")
+		}
+	}
+
+	switch config.NetworkTarget {
+	case "", chaos.NetworkSideSource, chaos.NetworkSideTarget, chaos.NetworkSideBoth:
+	default:
+		return fmt.Errorf("invalid networkTarget '%s': must be one of %s, %s, %s", config.NetworkTarget, chaos.NetworkSideSource, chaos.NetworkSideTarget, chaos.NetworkSideBoth)
+	}
+
+	switch config.TerminatePolicy {
+	case "", TerminatePolicyDelete, TerminatePolicyPause, TerminatePolicyKeep:
+	default:
+		return fmt.Errorf("invalid terminatePolicy '%s': must be one of %s, %s, %s", config.TerminatePolicy, TerminatePolicyDelete, TerminatePolicyPause, TerminatePolicyKeep)
+	}
+
+	switch config.ExecutionMode {
+	case "", ExecutionModeParallel, ExecutionModeSequential:
+	default:
+		return fmt.Errorf("invalid executionMode '%s': must be one of %s, %s", config.ExecutionMode, ExecutionModeParallel, ExecutionModeSequential)
+	}
+
+	switch config.Aggregation {
+	case "", AggregationAll, AggregationAny:
+	default:
+		return fmt.Errorf("invalid aggregation '%s': must be one of %s, %s", config.Aggregation, AggregationAll, AggregationAny)
+	}
+
+	switch config.Events {
+	case "", EventsAnalysisRun, EventsRollout, EventsNone:
+	default:
+		return fmt.Errorf("invalid events '%s': must be one of %s, %s, %s", config.Events, EventsAnalysisRun, EventsRollout, EventsNone)
+	}
+
 	return nil
-}
\ No newline at end of file
+}
+
+// parseTimeout returns the configured experiment timeout or the default
+func (r *RpcPlugin) parseTimeout(config *Config) time.Duration {
+	if config.Timeout != "" {
+		if parsedTimeout, err := time.ParseDuration(config.Timeout); err == nil {
+			return parsedTimeout
+		}
+		r.LogCtx.Warnf("Invalid timeout format '%s', using default %v", config.Timeout, DefaultTimeout)
+	}
+	return DefaultTimeout
+}
+
+// Shutdown cancels every in-flight run of this process. Experiments are left running so a
+// restarted plugin can re-adopt them.
+func (r *RpcPlugin) Shutdown() {
+	r.LogCtx.Info("Shutting down Chaos Mesh plugin")
+	r.stopOrphanSweep()
+	r.runs.cancelAll()
+}
+
+// watch follows an experiment in the background until it finishes, its deadline passes or
+// the run is cancelled, and records the outcome on the run for Resume
+func (r *RpcPlugin) watch(chaosClient *chaos.Client, run *inflightRun, ref chaos.ExperimentRef, deadline time.Time) {
+	experiment, err := chaosClient.WatchExperiment(run.ctx, ref, time.Until(deadline))
+	if err != nil && run.ctx.Err() == nil {
+		r.LogCtx.Warnf("Watch of chaos experiment %s/%s ended: %v", ref.Namespace, ref.Name, err)
+	}
+	run.setResult(ref, experiment, err)
+}
+
+// applyTerminatePolicy deletes, pauses or keeps a running experiment and returns the action taken
+func (r *RpcPlugin) applyTerminatePolicy(ctx context.Context, chaosClient *chaos.Client, policy string, ref chaos.ExperimentRef) string {
+	switch policy {
+	case TerminatePolicyKeep:
+		r.LogCtx.Infof("Keeping chaos experiment %s/%s after termination", ref.Namespace, ref.Name)
+		return "kept"
+	case TerminatePolicyPause:
+		if err := chaosClient.PauseExperiment(ctx, ref); err != nil {
+			r.LogCtx.Warnf("Failed to pause experiment during termination: %v", err)
+			return "pauseFailed"
+		}
+		r.LogCtx.Infof("Paused chaos experiment during termination: %s/%s", ref.Namespace, ref.Name)
+		return "paused"
+	default:
+		if err := chaosClient.DeleteExperiment(ctx, ref); err != nil {
+			r.LogCtx.Warnf("Failed to cleanup experiment during termination: %v", err)
+			return "deleteFailed"
+		}
+		r.LogCtx.Infof("Cleaned up chaos experiment during termination: %s/%s", ref.Namespace, ref.Name)
+		return "deleted"
+	}
+}
+
+// cleanup deletes the experiment if the configuration asks for it
+func (r *RpcPlugin) cleanup(ctx context.Context, chaosClient *chaos.Client, events *eventRecorder, config *Config, ref chaos.ExperimentRef) {
+	if !config.CleanupOnFinish {
+		return
+	}
+	if err := chaosClient.DeleteExperiment(ctx, ref); err != nil {
+		r.LogCtx.Warnf("Failed to cleanup experiment: %v", err)
+		events.recordStop(ctx, ref, "deleteFailed")
+	} else {
+		r.LogCtx.Infof("Cleaned up chaos experiment: %s/%s", ref.Namespace, ref.Name)
+		events.recordStop(ctx, ref, "deleted")
+	}
+}
+
+// requeue asks the controller to resume the measurement after DefaultResumeInterval
+func requeue(measurement v1alpha1.Measurement) v1alpha1.Measurement {
+	resumeAt := metav1.NewTime(time.Now().Add(DefaultResumeInterval))
+	measurement.ResumeAt = &resumeAt
+	return measurement
+}
+
+// deadlinePassed reports whether the deadline recorded in the measurement has expired
+func deadlinePassed(measurement v1alpha1.Measurement, timeout time.Duration) bool {
+	deadline, err := time.Parse(time.RFC3339, measurement.Metadata[metadataDeadline])
+	if err != nil {
+		// Measurements without a readable deadline fall back to the configured timeout
+		if measurement.StartedAt == nil {
+			return false
+		}
+		deadline = measurement.StartedAt.Add(timeout)
+	}
+	return time.Now().After(deadline)
+}
@@ -1,11 +1,23 @@
 package plugin
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestParseConfig(t *testing.T) {
@@ -55,6 +67,10 @@ func TestParseConfig(t *testing.T) {
 	if !parsedConfig.CleanupOnFinish {
 		t.Errorf("Expected CleanupOnFinish to be true, got false")
 	}
+
+	if parsedConfig.TerminatePolicy != TerminatePolicyDelete {
+		t.Errorf("Expected TerminatePolicy to default to '%s', got '%s'", TerminatePolicyDelete, parsedConfig.TerminatePolicy)
+	}
 }
 
 func TestValidateConfig(t *testing.T) {
@@ -121,6 +137,61 @@ func TestValidateConfig(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected validation to fail for invalid timeout format")
 	}
+
+	// Test invalid terminate policy
+	invalidConfig5 := &Config{
+		ChaosExperimentCRD:    "apiVersion: chaos-mesh.org/v1alpha1\nkind: PodChaos",
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+		TerminatePolicy:       "freeze",
+	}
+
+	err = plugin.validateConfig(invalidConfig5)
+	if err == nil {
+		t.Errorf("Expected validation to fail for invalid terminatePolicy")
+	}
+
+	// Test experiments list
+	validMulti := &Config{
+		Experiments: []ExperimentConfig{
+			{Name: "kill", ChaosExperimentCRD: "apiVersion: chaos-mesh.org/v1alpha1\nkind: PodChaos"},
+			{Name: "delay", ChaosExperimentCRD: "apiVersion: chaos-mesh.org/v1alpha1\nkind: NetworkChaos"},
+		},
+		ExecutionMode:         ExecutionModeSequential,
+		Aggregation:           AggregationAny,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+	}
+	if err := plugin.validateConfig(validMulti); err != nil {
+		t.Errorf("Expected valid experiments list, got error: %v", err)
+	}
+
+	invalidMulti := []struct {
+		name   string
+		mutate func(c *Config)
+	}{
+		{"both chaosExperimentCRD and experiments", func(c *Config) { c.ChaosExperimentCRD = "kind: PodChaos" }},
+		{"duplicate experiment names", func(c *Config) { c.Experiments[1].Name = "kill" }},
+		{"missing experiment name", func(c *Config) { c.Experiments[0].Name = "" }},
+		{"invalid executionMode", func(c *Config) { c.ExecutionMode = "random" }},
+		{"invalid aggregation", func(c *Config) { c.Aggregation = "most" }},
+		{"invalid selectorMergeStrategy", func(c *Config) { c.SelectorMergeStrategy = "override" }},
+		{"invalid experimentNamespace", func(c *Config) { c.ExperimentNamespace = "Team_A" }},
+		{"invalid targetNamespace", func(c *Config) { c.TargetNamespace = "team.a" }},
+		{"invalid networkTarget", func(c *Config) { c.NetworkTarget = "destination" }},
+		{"baseline equals target", func(c *Config) { c.BaselineReplicaSetValue = c.TargetReplicaSetValue }},
+		{"baseline with aggregation any", func(c *Config) { c.BaselineReplicaSetValue = "stable"; c.Aggregation = AggregationAny }},
+		{"invalid comparison", func(c *Config) { c.Comparison = "latency" }},
+		{"negative comparisonTolerance", func(c *Config) { c.ComparisonTolerance = "-5s" }},
+	}
+	for _, test := range invalidMulti {
+		config := *validMulti
+		config.Experiments = append([]ExperimentConfig(nil), validMulti.Experiments...)
+		test.mutate(&config)
+		if err := plugin.validateConfig(&config); err == nil {
+			t.Errorf("Expected validation to fail for %s", test.name)
+		}
+	}
 }
 
 func TestGetMetadata(t *testing.T) {
@@ -171,4 +242,423 @@ spec:
 	if metadata["experimentKind"] != "PodChaos" {
 		t.Errorf("Expected experimentKind to be 'PodChaos', got '%s'", metadata["experimentKind"])
 	}
-}
\ No newline at end of file
+}
+func TestDeadlinePassed(t *testing.T) {
+	past := metav1.NewTime(time.Now().Add(-time.Hour))
+	tenMinutesAgo := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+
+	tests := []struct {
+		name        string
+		measurement v1alpha1.Measurement
+		timeout     time.Duration
+		expected    bool
+	}{
+		{
+			name: "Deadline in the future",
+			measurement: v1alpha1.Measurement{
+				Metadata: map[string]string{"deadline": time.Now().Add(time.Minute).UTC().Format(time.RFC3339)},
+			},
+			expected: false,
+		},
+		{
+			name: "Deadline in the past",
+			measurement: v1alpha1.Measurement{
+				Metadata: map[string]string{"deadline": time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)},
+			},
+			expected: true,
+		},
+		{
+			name: "No deadline falls back to the configured timeout",
+			measurement: v1alpha1.Measurement{
+				StartedAt: &past,
+			},
+			timeout:  DefaultTimeout,
+			expected: true,
+		},
+		{
+			name: "No deadline honours a timeout longer than the default",
+			measurement: v1alpha1.Measurement{
+				StartedAt: &tenMinutesAgo,
+			},
+			timeout:  20 * time.Minute,
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := deadlinePassed(test.measurement, test.timeout); got != test.expected {
+				t.Errorf("Expected deadlinePassed=%t, got %t", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestResumeMissingExperiment(t *testing.T) {
+	plugin, _ := newTestPlugin()
+
+	configBytes, err := json.Marshal(Config{
+		ChaosExperimentCRD:    "apiVersion: chaos-mesh.org/v1alpha1\nkind: PodChaos",
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal config: %v", err)
+	}
+
+	metric := v1alpha1.Metric{
+		Provider: v1alpha1.MetricProvider{
+			Plugin: map[string]json.RawMessage{
+				PluginName: configBytes,
+			},
+		},
+	}
+
+	// A measurement without experiment metadata and no tracked experiment cannot be resumed
+	measurement := plugin.Resume(&v1alpha1.AnalysisRun{}, metric, v1alpha1.Measurement{Phase: v1alpha1.AnalysisPhaseRunning})
+	if measurement.Phase != v1alpha1.AnalysisPhaseError {
+		t.Errorf("Expected phase %s, got %s", v1alpha1.AnalysisPhaseError, measurement.Phase)
+	}
+
+	// Completed measurements are returned untouched
+	completed := v1alpha1.Measurement{Phase: v1alpha1.AnalysisPhaseSuccessful}
+	measurement = plugin.Resume(&v1alpha1.AnalysisRun{}, metric, completed)
+	if measurement.Phase != v1alpha1.AnalysisPhaseSuccessful {
+		t.Errorf("Expected phase %s, got %s", v1alpha1.AnalysisPhaseSuccessful, measurement.Phase)
+	}
+}
+
+func TestExperimentMetadataRoundTrip(t *testing.T) {
+	experiment := &unstructured.Unstructured{}
+	experiment.SetAPIVersion("chaos-mesh.org/v1alpha1")
+	experiment.SetKind("PodChaos")
+	experiment.SetNamespace("default")
+	experiment.SetName("pod-kill")
+	experiment.SetUID("uid-1")
+
+	deadline := time.Now().Add(time.Minute)
+	measurement := v1alpha1.Measurement{}
+	setExperimentRef(&measurement, chaos.RefFor(experiment))
+	setDeadline(&measurement, deadline)
+
+	ref, ok := experimentRefFromMetadata(measurement)
+	if !ok {
+		t.Fatalf("Expected experiment reference in metadata")
+	}
+	if ref != chaos.RefFor(experiment) {
+		t.Errorf("Expected reference %+v, got %+v", chaos.RefFor(experiment), ref)
+	}
+	if measurement.Metadata["deadline"] != deadline.UTC().Format(time.RFC3339) {
+		t.Errorf("Expected deadline to be recorded, got '%s'", measurement.Metadata["deadline"])
+	}
+
+	if _, ok := experimentRefFromMetadata(v1alpha1.Measurement{}); ok {
+		t.Errorf("Expected no reference for empty metadata")
+	}
+}
+
+var podChaosGVR = schema.GroupVersionResource{Group: "chaos-mesh.org", Version: "v1alpha1", Resource: "podchaos"}
+var workflowGVR = schema.GroupVersionResource{Group: "chaos-mesh.org", Version: "v1alpha1", Resource: "workflows"}
+var workflowNodeGVR = schema.GroupVersionResource{Group: "chaos-mesh.org", Version: "v1alpha1", Resource: "workflownodes"}
+var scheduleGVR = schema.GroupVersionResource{Group: "chaos-mesh.org", Version: "v1alpha1", Resource: "schedules"}
+var eventGVR = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+var podGVR = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+
+// newTestPlugin returns a plugin sharing a Chaos Mesh client backed by a fake dynamic client
+func newTestPlugin() (*RpcPlugin, *fake.FakeDynamicClient) {
+	logCtx := *log.WithFields(log.Fields{"test": "plugin"})
+	listKinds := map[schema.GroupVersionResource]string{
+		podChaosGVR:     "PodChaosList",
+		workflowGVR:     "WorkflowList",
+		workflowNodeGVR: "WorkflowNodeList",
+		scheduleGVR:     "ScheduleList",
+		eventGVR:        "EventList",
+		podGVR:          "PodList",
+	}
+	// Garbage collection lists every chaos kind
+	for _, resource := range []string{"networkchaos", "stresschaos", "iochaos", "timechaos", "kernelchaos", "dnschaos", "httpchaos", "jvmchaos", "blockchaos", "physicalmachinechaos", "awschaos", "gcpchaos", "azurechaos"} {
+		listKinds[schema.GroupVersionResource{Group: "chaos-mesh.org", Version: "v1alpha1", Resource: resource}] = resource + "List"
+	}
+	dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+
+	plugin := &RpcPlugin{LogCtx: logCtx}
+	plugin.SetClient(chaos.NewClientWithDynamic(dynamicClient, logCtx))
+	return plugin, dynamicClient
+}
+
+// newTestMetric returns a metric carrying the plugin configuration
+func newTestMetric(t *testing.T, config Config) v1alpha1.Metric {
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("Failed to marshal config: %v", err)
+	}
+	return v1alpha1.Metric{
+		Name: "chaos-mesh-test",
+		Provider: v1alpha1.MetricProvider{
+			Plugin: map[string]json.RawMessage{
+				PluginName: configBytes,
+			},
+		},
+	}
+}
+
+// waitForWatch blocks until the fake client has served count watch requests
+func waitForWatch(t *testing.T, dynamicClient *fake.FakeDynamicClient, count int) {
+	for i := 0; i < 100; i++ {
+		watches := 0
+		for _, action := range dynamicClient.Actions() {
+			if action.GetVerb() == "watch" {
+				watches++
+			}
+		}
+		if watches >= count {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for the experiment watch to start")
+}
+
+// resumeUntilCompleted resumes the measurement until it completes, giving the background
+// watch time to observe changes made by the test
+func resumeUntilCompleted(plugin *RpcPlugin, analysisRun *v1alpha1.AnalysisRun, metric v1alpha1.Metric, measurement v1alpha1.Measurement) v1alpha1.Measurement {
+	for i := 0; i < 100 && !measurement.Phase.Completed(); i++ {
+		measurement = plugin.Resume(analysisRun, metric, measurement)
+		time.Sleep(10 * time.Millisecond)
+	}
+	return measurement
+}
+
+const testPodChaos = `apiVersion: chaos-mesh.org/v1alpha1
+kind: PodChaos
+metadata:
+  name: pod-kill
+  namespace: default
+spec:
+  action: pod-kill
+  mode: one
+  selector:
+    namespaces:
+      - default`
+
+func TestRunAndResume(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	metric := newTestMetric(t, Config{
+		ChaosExperimentCRD:    testPodChaos,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+		Timeout:               "5m",
+		CleanupOnFinish:       true,
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+	ctx := context.Background()
+
+	measurement := plugin.Run(analysisRun, metric)
+	if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+	}
+	if measurement.ResumeAt == nil {
+		t.Errorf("Expected ResumeAt to be set on a running measurement")
+	}
+	experimentName := measurement.Metadata["experimentName"]
+	if !strings.HasPrefix(experimentName, "pod-kill-") {
+		t.Errorf("Expected experimentName to be suffixed 'pod-kill', got '%s'", experimentName)
+	}
+
+	// Still running: the measurement stays open
+	measurement = plugin.Resume(analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+	}
+
+	// Finish the experiment once the background watch is established
+	waitForWatch(t, dynamicClient, 1)
+	experiment, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Get(ctx, experimentName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get experiment: %v", err)
+	}
+	unstructured.SetNestedField(experiment.Object, "Finished", "status", "experiment", "phase")
+	if _, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Update(ctx, experiment, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update experiment: %v", err)
+	}
+
+	measurement = resumeUntilCompleted(plugin, analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseSuccessful {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	}
+	if measurement.FinishedAt == nil {
+		t.Errorf("Expected FinishedAt to be set on a completed measurement")
+	}
+	var value measurementResult
+	if err := json.Unmarshal([]byte(measurement.Value), &value); err != nil || !value.Success {
+		t.Errorf("Expected a successful JSON result value, got '%s' (%v)", measurement.Value, err)
+	}
+
+	_, err = dynamicClient.Resource(podChaosGVR).Namespace("default").Get(ctx, experimentName, metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("Expected experiment to be cleaned up, got %v", err)
+	}
+	if plugin.runs.get(runKeyFor(analysisRun, metric)) != nil {
+		t.Errorf("Expected the run to be dropped once the measurement completed")
+	}
+}
+
+func TestGarbageCollectKeepsInflightRun(t *testing.T) {
+	plugin, _ := newTestPlugin()
+	metric := newTestMetric(t, Config{
+		ChaosExperimentCRD:    testPodChaos,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+	key := runKeyFor(analysisRun, metric)
+
+	measurement := plugin.Run(analysisRun, metric)
+	run := plugin.runs.get(key)
+	if run == nil {
+		t.Fatalf("Expected the run to be registered")
+	}
+	plugin.GarbageCollect(analysisRun, metric, 10)
+	if plugin.runs.get(key) != run || run.ctx.Err() != nil {
+		t.Errorf("Expected garbage collection to leave the in-flight run alone")
+	}
+
+	plugin.Terminate(analysisRun, metric, measurement)
+	if plugin.runs.get(key) != nil || run.ctx.Err() == nil {
+		t.Errorf("Expected the run to be cancelled and dropped once terminated")
+	}
+}
+
+func TestRunAdoptsExistingExperiment(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	metric := newTestMetric(t, Config{
+		ChaosExperimentCRD:    testPodChaos,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+
+	first := plugin.Run(analysisRun, metric)
+	if first.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, first.Phase, first.Message)
+	}
+
+	// A retried Run of the same measurement adopts the experiment
+	retried := plugin.Run(analysisRun, metric)
+	if retried.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, retried.Phase, retried.Message)
+	}
+	if retried.Metadata["experimentUID"] != first.Metadata["experimentUID"] {
+		t.Errorf("Expected retried Run to adopt experiment %s, got %s", first.Metadata["experimentUID"], retried.Metadata["experimentUID"])
+	}
+
+	// The next measurement of the metric gets its own experiment
+	analysisRun.Status.MetricResults = []v1alpha1.MetricResult{{Name: metric.Name, Count: 1}}
+	next := plugin.Run(analysisRun, metric)
+	if next.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, next.Phase, next.Message)
+	}
+	if next.Metadata["experimentName"] == first.Metadata["experimentName"] {
+		t.Errorf("Expected a new experiment for the next measurement, got %s again", next.Metadata["experimentName"])
+	}
+
+	list, err := dynamicClient.Resource(podChaosGVR).Namespace("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list experiments: %v", err)
+	}
+	if len(list.Items) != 2 {
+		t.Errorf("Expected 2 experiments, got %d", len(list.Items))
+	}
+	plugin.Shutdown()
+}
+
+func TestTerminateCleanupFailed(t *testing.T) {
+	for _, policy := range []string{TerminatePolicyDelete, TerminatePolicyPause} {
+		t.Run(policy, func(t *testing.T) {
+			plugin, dynamicClient := newTestPlugin()
+			metric := newTestMetric(t, Config{
+				ChaosExperimentCRD:    testPodChaos,
+				TargetReplicaSetLabel: "rollouts-pod-template-hash",
+				TargetReplicaSetValue: "abc123",
+				TerminatePolicy:       policy,
+			})
+			analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+
+			measurement := plugin.Run(analysisRun, metric)
+			name := measurement.Metadata["experimentName"]
+			for _, verb := range []string{"delete", "update", "patch"} {
+				dynamicClient.PrependReactor(verb, "podchaos", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, fmt.Errorf("forbidden")
+				})
+			}
+			measurement = plugin.Terminate(analysisRun, metric, measurement)
+
+			if measurement.Phase != v1alpha1.AnalysisPhaseError || measurement.FinishedAt == nil {
+				t.Fatalf("Expected the terminated measurement to be an error, got phase %s", measurement.Phase)
+			}
+			expected := "failed to stop chaos experiments default/" + name + ", the fault may still be active"
+			if measurement.Message != expected {
+				t.Errorf("Expected message '%s', got '%s'", expected, measurement.Message)
+			}
+			if measurement.Metadata["terminateAction"] != policy+"Failed" {
+				t.Errorf("Expected terminateAction '%sFailed', got '%s'", policy, measurement.Metadata["terminateAction"])
+			}
+		})
+	}
+}
+
+func TestTerminatePolicy(t *testing.T) {
+	tests := []struct {
+		policy         string
+		expectedAction string
+		expectExists   bool
+		expectPaused   bool
+	}{
+		{policy: TerminatePolicyDelete, expectedAction: "deleted"},
+		{policy: TerminatePolicyPause, expectedAction: "paused", expectExists: true, expectPaused: true},
+		{policy: TerminatePolicyKeep, expectedAction: "kept", expectExists: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.policy, func(t *testing.T) {
+			plugin, dynamicClient := newTestPlugin()
+			metric := newTestMetric(t, Config{
+				ChaosExperimentCRD:    testPodChaos,
+				TargetReplicaSetLabel: "rollouts-pod-template-hash",
+				TargetReplicaSetValue: "abc123",
+				TerminatePolicy:       test.policy,
+			})
+			analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+
+			measurement := plugin.Run(analysisRun, metric)
+			run := plugin.runs.get(runKeyFor(analysisRun, metric))
+			measurement = plugin.Terminate(analysisRun, metric, measurement)
+
+			if run == nil || run.ctx.Err() == nil {
+				t.Errorf("Expected Terminate to cancel the in-flight run")
+			}
+
+			if measurement.Phase != v1alpha1.AnalysisPhaseSuccessful || measurement.FinishedAt == nil {
+				t.Errorf("Expected terminated measurement to be finished, got phase %s", measurement.Phase)
+			}
+			if measurement.Metadata["terminateAction"] != test.expectedAction {
+				t.Errorf("Expected terminateAction '%s', got '%s'", test.expectedAction, measurement.Metadata["terminateAction"])
+			}
+
+			experiment, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Get(context.Background(), measurement.Metadata["experimentName"], metav1.GetOptions{})
+			if !test.expectExists {
+				if !errors.IsNotFound(err) {
+					t.Errorf("Expected experiment to be deleted, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected experiment to exist: %v", err)
+			}
+			paused := experiment.GetAnnotations()[chaos.AnnotationPause] == "true"
+			if paused != test.expectPaused {
+				t.Errorf("Expected paused=%t, got %t", test.expectPaused, paused)
+			}
+		})
+	}
+}
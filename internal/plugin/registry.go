@@ -0,0 +1,184 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// runKey identifies the in-flight run of a metric within an AnalysisRun
+type runKey struct {
+	analysisRunUID string
+	metricName     string
+}
+
+// runKeyFor returns the registry key for a measurement of metric in analysisRun
+func runKeyFor(analysisRun *v1alpha1.AnalysisRun, metric v1alpha1.Metric) runKey {
+	return runKey{
+		analysisRunUID: string(analysisRun.UID),
+		metricName:     metric.Name,
+	}
+}
+
+// inflightRun tracks the background watches of a running measurement and guarantees each of
+// its experiments is cleaned up exactly once, whichever of Resume or Terminate gets there first
+type inflightRun struct {
+	key    runKey
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	watches map[chaos.ExperimentRef]*watchResult
+	cleaned map[chaos.ExperimentRef]bool
+}
+
+// watchResult is the outcome of the background watch of a single experiment
+type watchResult struct {
+	done       bool
+	experiment *unstructured.Unstructured
+	err        error
+}
+
+// track records that a background watch of the experiment has started
+func (run *inflightRun) track(ref chaos.ExperimentRef) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	if run.watches == nil {
+		run.watches = make(map[chaos.ExperimentRef]*watchResult)
+	}
+	run.watches[ref] = &watchResult{}
+}
+
+// setResult records the outcome of the background watch of the experiment
+func (run *inflightRun) setResult(ref chaos.ExperimentRef, experiment *unstructured.Unstructured, err error) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	if run.watches == nil {
+		run.watches = make(map[chaos.ExperimentRef]*watchResult)
+	}
+	run.watches[ref] = &watchResult{done: true, experiment: experiment, err: err}
+}
+
+// result returns the outcome of the background watch of the experiment, if it has one yet. tracked is false when this run never watched the experiment.
+func (run *inflightRun) result(ref chaos.ExperimentRef) (experiment *unstructured.Unstructured, done, tracked bool, err error) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	watched, tracked := run.watches[ref]
+	if !tracked {
+		return nil, false, false, nil
+	}
+	return watched.experiment, watched.done, true, watched.err
+}
+
+// cleanupOnce runs cleanup for the experiment unless it already ran
+func (run *inflightRun) cleanupOnce(ref chaos.ExperimentRef, cleanup func()) {
+	run.mu.Lock()
+	if run.cleaned == nil {
+		run.cleaned = make(map[chaos.ExperimentRef]bool)
+	}
+	if run.cleaned[ref] {
+		run.mu.Unlock()
+		return
+	}
+	run.cleaned[ref] = true
+	run.mu.Unlock()
+	cleanup()
+}
+
+// cleanedRefs returns the experiments the run cleaned up
+func (run *inflightRun) cleanedRefs() []chaos.ExperimentRef {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	refs := make([]chaos.ExperimentRef, 0, len(run.cleaned))
+	for ref := range run.cleaned {
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// maxRecentlyCleaned bounds how many experiments cleaned up by finished runs are remembered
+const maxRecentlyCleaned = 256
+
+// runRegistry holds the in-flight runs of this plugin process. Runs are dropped once their
+// measurement completes or terminates; the experiments they cleaned up are remembered for a
+// while longer so a late Terminate does not clean up twice.
+type runRegistry struct {
+	mu   sync.Mutex
+	runs map[runKey]*inflightRun
+	// cleaned holds, oldest first, the experiments cleaned up by finished runs
+	cleaned []chaos.ExperimentRef
+}
+
+// start registers a new run for key, cancelling any previous run registered under it
+func (reg *runRegistry) start(key runKey) *inflightRun {
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &inflightRun{key: key, ctx: ctx, cancel: cancel}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if reg.runs == nil {
+		reg.runs = make(map[runKey]*inflightRun)
+	}
+	if previous, exists := reg.runs[key]; exists {
+		previous.cancel()
+	}
+	reg.runs[key] = run
+	return run
+}
+
+// get returns the run registered under key, or nil if this process has none
+func (reg *runRegistry) get(key runKey) *inflightRun {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	return reg.runs[key]
+}
+
+// remove cancels and forgets the run registered under key
+func (reg *runRegistry) remove(key runKey) {
+	if run := reg.get(key); run != nil {
+		reg.finish(run)
+	}
+}
+
+// finish cancels run and forgets it, unless a newer run replaced it already, remembering the
+// experiments it cleaned up
+func (reg *runRegistry) finish(run *inflightRun) {
+	run.cancel()
+	cleaned := run.cleanedRefs()
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if reg.runs[run.key] == run {
+		delete(reg.runs, run.key)
+	}
+	reg.cleaned = append(reg.cleaned, cleaned...)
+	if overflow := len(reg.cleaned) - maxRecentlyCleaned; overflow > 0 {
+		reg.cleaned = append([]chaos.ExperimentRef(nil), reg.cleaned[overflow:]...)
+	}
+}
+
+// cleanedRecently reports whether a finished run cleaned up the experiment
+func (reg *runRegistry) cleanedRecently(ref chaos.ExperimentRef) bool {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	for _, cleaned := range reg.cleaned {
+		if cleaned == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// cancelAll cancels every registered run. Experiments are left in place so that a new
+// plugin process can re-adopt them.
+func (reg *runRegistry) cancelAll() {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	for key, run := range reg.runs {
+		run.cancel()
+		delete(reg.runs, key)
+	}
+}
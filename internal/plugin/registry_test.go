@@ -0,0 +1,81 @@
+package plugin
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
+)
+
+func TestRunRegistry(t *testing.T) {
+	var registry runRegistry
+	key := runKey{analysisRunUID: "run-uid", metricName: "chaos-mesh-test"}
+
+	first := registry.start(key)
+	second := registry.start(key)
+	if first.ctx.Err() == nil {
+		t.Errorf("Expected starting a new run to cancel the previous one")
+	}
+	if registry.get(key) != second {
+		t.Errorf("Expected the latest run to be registered")
+	}
+
+	podKill := chaos.ExperimentRef{Kind: "PodChaos", Namespace: "default", Name: "pod-kill", UID: "uid-1"}
+	podFailure := chaos.ExperimentRef{Kind: "PodChaos", Namespace: "default", Name: "pod-failure", UID: "uid-2"}
+
+	// Cleanup runs exactly once per experiment
+	cleanups := 0
+	second.cleanupOnce(podKill, func() { cleanups++ })
+	second.cleanupOnce(podKill, func() { cleanups++ })
+	second.cleanupOnce(podFailure, func() { cleanups++ })
+	if cleanups != 2 {
+		t.Errorf("Expected cleanup to run once per experiment, ran %d times", cleanups)
+	}
+
+	// Watch results are kept per experiment
+	if _, _, tracked, _ := second.result(podKill); tracked {
+		t.Errorf("Expected an unwatched experiment not to be tracked")
+	}
+	second.track(podKill)
+	if _, done, tracked, _ := second.result(podKill); !tracked || done {
+		t.Errorf("Expected a started watch to be tracked and pending, got tracked=%t done=%t", tracked, done)
+	}
+	second.setResult(podKill, nil, nil)
+	if _, done, _, _ := second.result(podKill); !done {
+		t.Errorf("Expected the watch result to be recorded")
+	}
+
+	// Finished runs are dropped, the experiments they cleaned up are remembered
+	registry.finish(second)
+	if second.ctx.Err() == nil || registry.get(key) != nil {
+		t.Errorf("Expected finish to cancel and drop the run")
+	}
+	if !registry.cleanedRecently(podKill) || !registry.cleanedRecently(podFailure) {
+		t.Errorf("Expected the experiments cleaned up by the finished run to be remembered")
+	}
+	for i := 0; i < maxRecentlyCleaned; i++ {
+		run := registry.start(key)
+		run.cleanupOnce(chaos.ExperimentRef{Kind: "PodChaos", Namespace: "default", Name: fmt.Sprintf("pod-kill-%d", i)}, func() {})
+		registry.finish(run)
+	}
+	if registry.cleanedRecently(podKill) || len(registry.cleaned) != maxRecentlyCleaned {
+		t.Errorf("Expected the remembered experiments to be bounded, got %d", len(registry.cleaned))
+	}
+
+	// Finishing a replaced run keeps its replacement registered
+	replaced := registry.start(key)
+	latest := registry.start(key)
+	registry.finish(replaced)
+	if registry.get(key) != latest {
+		t.Errorf("Expected finishing a replaced run to keep the latest one")
+	}
+
+	third := registry.start(runKey{analysisRunUID: "run-uid", metricName: "other"})
+	registry.cancelAll()
+	if third.ctx.Err() == nil {
+		t.Errorf("Expected cancelAll to cancel every run")
+	}
+	if registry.get(key) != nil {
+		t.Errorf("Expected cancelAll to empty the registry")
+	}
+}
@@ -0,0 +1,111 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
+)
+
+// Limits keeping the target report within the size the AnalysisRun status and its
+// annotations can hold, even for experiments selecting hundreds of pods
+const (
+	// maxReportTargets is the number of targets recorded per experiment
+	maxReportTargets = 25
+	// maxReportBytes is the size of the JSON report recorded per experiment
+	maxReportBytes = 4096
+	// maxMessageTargets is the number of targets named per list in the measurement message
+	maxMessageTargets = 5
+)
+
+// Measurement metadata keys holding the target report of a metric with a single experiment
+const (
+	metadataTargets        = "targets"
+	metadataTargetsOmitted = "targetsOmitted"
+)
+
+// truncateTargets limits the targets recorded for an experiment. Targets that were never
+// recovered come first, then the injected ones, so truncation drops the least interesting
+// targets. It returns the kept targets and how many were omitted.
+func truncateTargets(targets []chaos.TargetReport) ([]chaos.TargetReport, int) {
+	rank := func(target chaos.TargetReport) int {
+		switch {
+		case target.NotRecovered():
+			return 0
+		case target.Injected:
+			return 1
+		default:
+			return 2
+		}
+	}
+	sorted := make([]chaos.TargetReport, len(targets))
+	copy(sorted, targets)
+	sort.SliceStable(sorted, func(i, j int) bool { return rank(sorted[i]) < rank(sorted[j]) })
+
+	kept := sorted[:min(len(sorted), maxReportTargets)]
+	for len(kept) > 0 {
+		data, err := json.Marshal(kept)
+		if err == nil && len(data) <= maxReportBytes {
+			break
+		}
+		kept = kept[:len(kept)-1]
+	}
+	return kept, len(targets) - len(kept)
+}
+
+// targetMessage summarises the targets hit by the experiments for the measurement message,
+// e.g. "injected 2 of 3 targets: default/web-0, default/web-1; not recovered: default/web-1"
+func targetMessage(states []experimentState) string {
+	var messages []string
+	for _, state := range states {
+		if state.Stats == nil || state.Stats.TargetCount == 0 {
+			continue
+		}
+
+		var injected, notRecovered []string
+		for _, target := range state.Targets {
+			if target.Injected {
+				injected = append(injected, target.Target)
+			}
+			if target.NotRecovered() {
+				notRecovered = append(notRecovered, target.Target)
+			}
+		}
+
+		message := fmt.Sprintf("injected %d of %d targets", state.Stats.InjectedCount, state.Stats.TargetCount)
+		if len(injected) > 0 {
+			message += ": " + nameList(injected, max(state.Stats.InjectedCount, len(injected)))
+		}
+		if len(notRecovered) > 0 {
+			message += "; not recovered: " + nameList(notRecovered, len(notRecovered))
+		}
+		if state.Name != "" {
+			message = fmt.Sprintf("experiment %s: %s", state.Name, message)
+		}
+		messages = append(messages, message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// nameList joins up to maxMessageTargets names, mentioning how many of total are left out
+func nameList(names []string, total int) string {
+	shown := names[:min(len(names), maxMessageTargets)]
+	list := strings.Join(shown, ", ")
+	if total > len(shown) {
+		list += fmt.Sprintf(" and %d more", total-len(shown))
+	}
+	return list
+}
+
+// joinMessages joins the non-empty messages
+func joinMessages(messages ...string) string {
+	var parts []string
+	for _, message := range messages {
+		if message != "" {
+			parts = append(parts, message)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
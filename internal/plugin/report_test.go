@@ -0,0 +1,93 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
+)
+
+func TestTruncateTargets(t *testing.T) {
+	var targets []chaos.TargetReport
+	for i := 0; i < 40; i++ {
+		targets = append(targets, chaos.TargetReport{Target: fmt.Sprintf("default/web-%d", i), Injected: true, Recovered: i != 30})
+	}
+	targets = append([]chaos.TargetReport{{Target: "default/idle"}}, targets...)
+
+	kept, omitted := truncateTargets(targets)
+	if len(kept) != maxReportTargets || omitted != len(targets)-maxReportTargets {
+		t.Fatalf("Expected %d targets kept and %d omitted, got %d and %d", maxReportTargets, len(targets)-maxReportTargets, len(kept), omitted)
+	}
+	if kept[0].Target != "default/web-30" {
+		t.Errorf("Expected the target that never recovered to come first, got %s", kept[0].Target)
+	}
+	for _, target := range kept {
+		if target.Target == "default/idle" {
+			t.Errorf("Expected the target that was never injected to be dropped first")
+		}
+	}
+
+	// Long target names are cut down to the byte limit
+	long := make([]chaos.TargetReport, 10)
+	for i := range long {
+		long[i] = chaos.TargetReport{Target: strings.Repeat("x", 1000), Injected: true}
+	}
+	kept, omitted = truncateTargets(long)
+	data, _ := json.Marshal(kept)
+	if len(data) > maxReportBytes || len(kept)+omitted != len(long) {
+		t.Errorf("Expected the report to fit in %d bytes, got %d bytes with %d omitted", maxReportBytes, len(data), omitted)
+	}
+}
+
+func TestTargetMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		states   []experimentState
+		expected string
+	}{
+		{
+			name:     "no statistics",
+			states:   []experimentState{{Phase: "Successful"}},
+			expected: "",
+		},
+		{
+			name: "single experiment",
+			states: []experimentState{{
+				Stats: &chaos.Stats{TargetCount: 3, InjectedCount: 2},
+				Targets: []chaos.TargetReport{
+					{Target: "default/web-1", Injected: true},
+					{Target: "default/web-0", Injected: true, Recovered: true},
+					{Target: "default/web-2"},
+				},
+			}},
+			expected: "injected 2 of 3 targets: default/web-1, default/web-0; not recovered: default/web-1",
+		},
+		{
+			name: "truncated list",
+			states: []experimentState{{
+				Name:  "kill",
+				Stats: &chaos.Stats{TargetCount: 8, InjectedCount: 8},
+				Targets: []chaos.TargetReport{
+					{Target: "a", Injected: true, Recovered: true},
+					{Target: "b", Injected: true, Recovered: true},
+					{Target: "c", Injected: true, Recovered: true},
+					{Target: "d", Injected: true, Recovered: true},
+					{Target: "e", Injected: true, Recovered: true},
+					{Target: "f", Injected: true, Recovered: true},
+				},
+				TargetsOmitted: 2,
+			}},
+			expected: "experiment kill: injected 8 of 8 targets: a, b, c, d, e and 3 more",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if message := targetMessage(tt.states); message != tt.expected {
+				t.Errorf("Expected message '%s', got '%s'", tt.expected, message)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/argoproj/argo-rollouts/utils/evaluate"
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
+)
+
+// measurementResult is the value of a completed measurement. It is recorded as JSON in the
+// measurement value and exposed as result to successCondition and failureCondition.
+type measurementResult struct {
+	// Success reports whether the chaos experiments themselves succeeded
+	Success bool `json:"success"`
+	chaos.Stats
+	// Runs summarises the runs of the schedules among the experiments
+	Runs *chaos.ScheduleRuns `json:"runs,omitempty"`
+	// Experiments holds the result of each entry of the experiments list
+	Experiments map[string]experimentResult `json:"experiments,omitempty"`
+	// Comparison compares the canary with the baseline when baselineReplicaSetValue is set
+	Comparison *comparison `json:"comparison,omitempty"`
+}
+
+// comparisonMessage returns the outcome of the comparison, if the experiments were compared
+func (m measurementResult) comparisonMessage() string {
+	if m.Comparison == nil {
+		return ""
+	}
+	return m.Comparison.Message
+}
+
+// experimentResult is the result of one entry of the experiments list
+type experimentResult struct {
+	Success bool                   `json:"success"`
+	Phase   v1alpha1.AnalysisPhase `json:"phase"`
+	chaos.Stats
+	Runs *chaos.ScheduleRuns `json:"runs,omitempty"`
+}
+
+// newMeasurementResult builds the measurement result from the experiment states. The
+// statistics of several experiments are combined by summing the counts and keeping the
+// longest durations.
+func newMeasurementResult(states []experimentState, success bool) measurementResult {
+	result := measurementResult{Success: success}
+	for _, state := range states {
+		var stats chaos.Stats
+		if state.Stats != nil {
+			stats = *state.Stats
+		}
+
+		result.Stats.Add(stats)
+		if state.Runs != nil {
+			if result.Runs == nil {
+				result.Runs = &chaos.ScheduleRuns{}
+			}
+			result.Runs.Add(*state.Runs)
+		}
+
+		if state.Name != "" {
+			if result.Experiments == nil {
+				result.Experiments = make(map[string]experimentResult)
+			}
+			result.Experiments[state.Name] = experimentResult{
+				Success: state.Phase == v1alpha1.AnalysisPhaseSuccessful,
+				Phase:   state.Phase,
+				Stats:   stats,
+				Runs:    state.Runs,
+			}
+		}
+	}
+	return result
+}
+
+// hasConditions reports whether the metric decides its outcome from the measurement value
+func hasConditions(metric v1alpha1.Metric) bool {
+	return metric.SuccessCondition != "" || metric.FailureCondition != ""
+}
+
+// evaluateResult evaluates the successCondition and failureCondition of the metric against
+// the measurement result
+func (r *RpcPlugin) evaluateResult(result measurementResult, metric v1alpha1.Metric) (v1alpha1.AnalysisPhase, error) {
+	// Round trip through JSON so conditions use the same field names as the measurement value
+	data, err := json.Marshal(result)
+	if err != nil {
+		return v1alpha1.AnalysisPhaseError, err
+	}
+	var value map[string]interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return v1alpha1.AnalysisPhaseError, err
+	}
+
+	phase, err := evaluate.EvaluateResult(value, metric, r.LogCtx)
+	if err != nil {
+		return v1alpha1.AnalysisPhaseError, fmt.Errorf("failed to evaluate result: %w", err)
+	}
+	return phase, nil
+}
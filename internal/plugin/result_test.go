@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestNewMeasurementResult(t *testing.T) {
+	states := []experimentState{
+		{Name: "kill", Phase: v1alpha1.AnalysisPhaseSuccessful, Stats: &chaos.Stats{TargetCount: 2, InjectedCount: 2, RecoveredCount: 2, RecoverySeconds: 3}},
+		{Name: "delay", Phase: v1alpha1.AnalysisPhaseFailed, Stats: &chaos.Stats{TargetCount: 1, InjectedCount: 1, RecoverySeconds: 7}},
+		{Name: "stress", Phase: phaseStopped},
+	}
+
+	result := newMeasurementResult(states, false)
+	if result.InjectedCount != 3 || result.RecoveredCount != 2 || result.TargetCount != 3 {
+		t.Errorf("Expected counts to be summed, got %+v", result.Stats)
+	}
+	if result.RecoverySeconds != 7 {
+		t.Errorf("Expected the longest recovery time, got %v", result.RecoverySeconds)
+	}
+	if !result.Experiments["kill"].Success || result.Experiments["delay"].Success {
+		t.Errorf("Expected per-experiment success to follow the experiment phase, got %+v", result.Experiments)
+	}
+
+	// Single experiments have no per-experiment breakdown
+	data, err := json.Marshal(newMeasurementResult([]experimentState{{Phase: v1alpha1.AnalysisPhaseSuccessful}}, true))
+	if err != nil {
+		t.Fatalf("Failed to marshal result: %v", err)
+	}
+	var value map[string]interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if _, exists := value["experiments"]; exists {
+		t.Errorf("Expected no experiments field for a single experiment, got %s", data)
+	}
+	if value["success"] != true || value["injectedCount"] != float64(0) {
+		t.Errorf("Expected flat success and statistics fields, got %s", data)
+	}
+}
+
+func TestEvaluateResult(t *testing.T) {
+	plugin := &RpcPlugin{LogCtx: *log.WithFields(log.Fields{"test": "plugin"})}
+	result := measurementResult{
+		Success: true,
+		Stats:   chaos.Stats{InjectedCount: 2, RecoveredCount: 2, RecoverySeconds: 12},
+	}
+
+	tests := []struct {
+		name             string
+		successCondition string
+		failureCondition string
+		expected         v1alpha1.AnalysisPhase
+	}{
+		{"Success condition met", "result.recoveredCount == result.injectedCount && result.recoverySeconds < 30", "", v1alpha1.AnalysisPhaseSuccessful},
+		{"Success condition not met", "result.recoverySeconds < 10", "", v1alpha1.AnalysisPhaseFailed},
+		{"Failure condition met", "", "result.recoveredCount < result.injectedCount || result.recoverySeconds > 10", v1alpha1.AnalysisPhaseFailed},
+		{"Invalid condition", "result.missing.field > 1", "", v1alpha1.AnalysisPhaseError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metric := v1alpha1.Metric{SuccessCondition: test.successCondition, FailureCondition: test.failureCondition}
+			phase, _ := plugin.evaluateResult(result, metric)
+			if phase != test.expected {
+				t.Errorf("Expected phase %s, got %s", test.expected, phase)
+			}
+		})
+	}
+}
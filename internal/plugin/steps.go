@@ -0,0 +1,733 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	metricutil "github.com/argoproj/argo-rollouts/utils/metric"
+	timeutil "github.com/argoproj/argo-rollouts/utils/time"
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// metadataExperiments is the measurement metadata key holding the state of every experiment
+// of a metric configured with an experiments list
+const metadataExperiments = "experiments"
+
+// metadataWorkflowNodes is the measurement metadata key holding the node tree of a workflow
+// run by a metric with a single experiment
+const metadataWorkflowNodes = "workflowNodes"
+
+// metadataScheduleRuns is the measurement metadata key holding the runs of a schedule run by
+// a metric with a single experiment
+const metadataScheduleRuns = "scheduleRuns"
+
+// metadataScheduleRunHistory is the measurement metadata key holding the runs of a schedule
+// run by a metric with a single experiment, so pruned runs are not forgotten
+const metadataScheduleRunHistory = "scheduleRunHistory"
+
+// metadataInjectedRecorded is the measurement metadata key marking that the ChaosInjected
+// Event of a metric with a single experiment was recorded
+const metadataInjectedRecorded = "injectedRecorded"
+
+// metadataSelectorWarning is the measurement metadata key warning that the target selector
+// overrode template labels of the experiment of a metric with a single experiment
+const metadataSelectorWarning = "selectorWarning"
+
+// metadataPinnedPods is the measurement metadata key holding the pods the experiment of a
+// metric with a single experiment was pinned to
+const metadataPinnedPods = "pinnedPods"
+
+// phaseStopped marks an experiment that was stopped because the outcome of the measurement
+// was decided, or the measurement terminated, before it finished
+const phaseStopped v1alpha1.AnalysisPhase = "Stopped"
+
+// experimentState is the persisted state of one experiment of a measurement
+type experimentState struct {
+	Name       string                 `json:"name"`
+	APIVersion string                 `json:"apiVersion,omitempty"`
+	Kind       string                 `json:"kind,omitempty"`
+	Namespace  string                 `json:"namespace,omitempty"`
+	Experiment string                 `json:"experiment,omitempty"`
+	UID        string                 `json:"uid,omitempty"`
+	Phase      v1alpha1.AnalysisPhase `json:"phase"`
+	Message    string                 `json:"message,omitempty"`
+	Action     string                 `json:"action,omitempty"`
+	Stats      *chaos.Stats           `json:"stats,omitempty"`
+	// Nodes is the node tree of a Chaos Mesh workflow
+	Nodes []chaos.WorkflowNodeStatus `json:"nodes,omitempty"`
+	// Runs summarises the runs spawned by a Chaos Mesh schedule
+	Runs *chaos.ScheduleRuns `json:"runs,omitempty"`
+	// RunHistory holds the outcome of the runs of a schedule seen so far, see observeSchedule
+	RunHistory *chaos.ScheduleRunHistory `json:"runHistory,omitempty"`
+	// Targets reports the pods or containers hit by the experiment, see truncateTargets
+	Targets        []chaos.TargetReport `json:"targets,omitempty"`
+	TargetsOmitted int                  `json:"targetsOmitted,omitempty"`
+	// SelectorWarning lists the template labels the target selector overrode, see selectorWarning
+	SelectorWarning string `json:"selectorWarning,omitempty"`
+	// Role is canary or baseline for the copies of the experiments run by a comparison
+	Role string `json:"role,omitempty"`
+	// PinnedPods are the pods, as namespace/name, the experiment was pinned to with pinTargetPods
+	PinnedPods []string `json:"pinnedPods,omitempty"`
+	// InjectedRecorded is set once the ChaosInjected Event was recorded, see recordInjected
+	InjectedRecorded bool `json:"injectedRecorded,omitempty"`
+}
+
+// ref returns the reference to the experiment, if one was created
+func (s experimentState) ref() (chaos.ExperimentRef, bool) {
+	if s.Experiment == "" {
+		return chaos.ExperimentRef{}, false
+	}
+	return chaos.ExperimentRef{
+		APIVersion: s.APIVersion,
+		Kind:       s.Kind,
+		Namespace:  s.Namespace,
+		Name:       s.Experiment,
+		UID:        k8stypes.UID(s.UID),
+	}, true
+}
+
+// setExperiment records the coordinates of the experiment object
+func (s *experimentState) setExperiment(experiment *unstructured.Unstructured) {
+	s.APIVersion = experiment.GetAPIVersion()
+	s.Kind = experiment.GetKind()
+	s.Namespace = experiment.GetNamespace()
+	s.Experiment = experiment.GetName()
+	s.UID = string(experiment.GetUID())
+}
+
+// finish records the final phase of the experiment
+func (s *experimentState) finish(phase v1alpha1.AnalysisPhase, message string) {
+	s.Phase = phase
+	s.Message = message
+}
+
+// finished reports whether the experiment reached a final phase
+func (s experimentState) finished() bool {
+	return s.Phase.Completed() || s.Phase == phaseStopped
+}
+
+// newExperimentStates returns the initial, not yet started, state of every configured experiment
+func newExperimentStates(config *Config) []experimentState {
+	specs := config.experimentSpecs()
+	states := make([]experimentState, len(specs))
+	for i, spec := range specs {
+		states[i] = experimentState{Name: spec.Name, Role: spec.role, Phase: v1alpha1.AnalysisPhasePending}
+	}
+	return states
+}
+
+// loadExperimentStates reads the experiment states back from the measurement. Measurements of
+// a single experiment keep the flat metadata keys and, without them, fall back to searching by
+// tracking labels. config may be nil when the metric configuration cannot be parsed.
+func loadExperimentStates(config *Config, measurement v1alpha1.Measurement) ([]experimentState, error) {
+	if data, exists := measurement.Metadata[metadataExperiments]; exists {
+		var states []experimentState
+		if err := json.Unmarshal([]byte(data), &states); err != nil {
+			return nil, fmt.Errorf("failed to read experiment states: %w", err)
+		}
+		return states, nil
+	}
+
+	if ref, ok := experimentRefFromMetadata(measurement); ok {
+		state := experimentState{
+			APIVersion:       ref.APIVersion,
+			Kind:             ref.Kind,
+			Namespace:        ref.Namespace,
+			Experiment:       ref.Name,
+			UID:              string(ref.UID),
+			Phase:            v1alpha1.AnalysisPhaseRunning,
+			InjectedRecorded: measurement.Metadata[metadataInjectedRecorded] == "true",
+		}
+		if data, exists := measurement.Metadata[metadataScheduleRunHistory]; exists {
+			if err := json.Unmarshal([]byte(data), &state.RunHistory); err != nil {
+				return nil, fmt.Errorf("failed to read schedule runs: %w", err)
+			}
+		}
+		return []experimentState{state}, nil
+	}
+
+	if config == nil || config.multiExperiment() || config.comparing() {
+		return nil, errNoExperiment
+	}
+	return []experimentState{{Phase: v1alpha1.AnalysisPhaseRunning}}, nil
+}
+
+// saveExperimentStates records the experiment states on the measurement
+func saveExperimentStates(measurement *v1alpha1.Measurement, states []experimentState) {
+	if measurement.Metadata == nil {
+		measurement.Metadata = make(map[string]string)
+	}
+	if len(states) == 1 && states[0].Name == "" {
+		if ref, ok := states[0].ref(); ok {
+			setExperimentRef(measurement, ref)
+		}
+		if len(states[0].Nodes) > 0 {
+			if data, err := json.Marshal(states[0].Nodes); err == nil {
+				measurement.Metadata[metadataWorkflowNodes] = string(data)
+			}
+		}
+		if states[0].SelectorWarning != "" {
+			measurement.Metadata[metadataSelectorWarning] = states[0].SelectorWarning
+		}
+		if len(states[0].PinnedPods) > 0 {
+			if data, err := json.Marshal(states[0].PinnedPods); err == nil {
+				measurement.Metadata[metadataPinnedPods] = string(data)
+			}
+		}
+		if states[0].Runs != nil {
+			if data, err := json.Marshal(states[0].Runs); err == nil {
+				measurement.Metadata[metadataScheduleRuns] = string(data)
+			}
+		}
+		if states[0].RunHistory != nil && !states[0].RunHistory.Empty() {
+			if data, err := json.Marshal(states[0].RunHistory); err == nil {
+				measurement.Metadata[metadataScheduleRunHistory] = string(data)
+			}
+		}
+		if states[0].InjectedRecorded {
+			measurement.Metadata[metadataInjectedRecorded] = "true"
+		}
+		if len(states[0].Targets) > 0 {
+			if data, err := json.Marshal(states[0].Targets); err == nil {
+				measurement.Metadata[metadataTargets] = string(data)
+			}
+			if states[0].TargetsOmitted > 0 {
+				measurement.Metadata[metadataTargetsOmitted] = fmt.Sprintf("%d", states[0].TargetsOmitted)
+			}
+		}
+		return
+	}
+	data, err := json.Marshal(states)
+	if err != nil {
+		// experimentState only holds plain data, so this cannot happen
+		return
+	}
+	measurement.Metadata[metadataExperiments] = string(data)
+}
+
+// aggregate decides the phase of the measurement from the experiment states, if the results
+// seen so far are enough to decide it
+func aggregate(states []experimentState, aggregation string) (v1alpha1.AnalysisPhase, bool) {
+	count := map[v1alpha1.AnalysisPhase]int{}
+	done := 0
+	for _, state := range states {
+		count[state.Phase]++
+		if state.finished() {
+			done++
+		}
+	}
+
+	if aggregation == AggregationAny {
+		switch {
+		case count[v1alpha1.AnalysisPhaseSuccessful] > 0:
+			return v1alpha1.AnalysisPhaseSuccessful, true
+		case done < len(states):
+			return "", false
+		case count[v1alpha1.AnalysisPhaseFailed] > 0:
+			return v1alpha1.AnalysisPhaseFailed, true
+		default:
+			return v1alpha1.AnalysisPhaseError, true
+		}
+	}
+
+	switch {
+	case count[v1alpha1.AnalysisPhaseFailed] > 0:
+		return v1alpha1.AnalysisPhaseFailed, true
+	case count[v1alpha1.AnalysisPhaseError] > 0:
+		return v1alpha1.AnalysisPhaseError, true
+	case count[v1alpha1.AnalysisPhaseSuccessful] == len(states):
+		return v1alpha1.AnalysisPhaseSuccessful, true
+	default:
+		return "", false
+	}
+}
+
+// failureMessage describes why the experiments did not succeed
+func failureMessage(states []experimentState) string {
+	var messages []string
+	for _, state := range states {
+		if state.Message == "" || (state.Phase != v1alpha1.AnalysisPhaseError && state.Phase != v1alpha1.AnalysisPhaseFailed) {
+			continue
+		}
+		if state.Name == "" {
+			messages = append(messages, state.Message)
+		} else {
+			messages = append(messages, fmt.Sprintf("experiment %s: %s", state.Name, state.Message))
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+// progress observes the running experiments, starts the next ones according to the execution
+// mode and completes the measurement once the aggregation rule decides its outcome
+func (r *RpcPlugin) progress(chaosClient *chaos.Client, analysisRun *v1alpha1.AnalysisRun, metric v1alpha1.Metric, config *Config, run *inflightRun, states []experimentState, measurement v1alpha1.Measurement) v1alpha1.Measurement {
+	ctx := context.Background()
+	specs := config.experimentSpecs()
+	events := r.eventRecorder(chaosClient, analysisRun, config)
+
+	for i := range states {
+		if states[i].Phase == v1alpha1.AnalysisPhaseRunning {
+			r.observeExperiment(ctx, chaosClient, events, analysisRun, metric, config, run, specs, &states[i], &measurement)
+		}
+	}
+
+	for {
+		phase, decided := aggregate(states, config.Aggregation)
+		if config.comparing() {
+			phase, decided = aggregateComparison(states, config)
+		}
+		if decided {
+			return r.completeMeasurement(ctx, chaosClient, events, metric, config, run, states, phase, measurement)
+		}
+		if !r.startPending(chaosClient, events, analysisRun, metric, config, run, specs, states, measurement) {
+			break
+		}
+	}
+
+	saveExperimentStates(&measurement, states)
+	measurement.Phase = v1alpha1.AnalysisPhaseRunning
+	return requeue(measurement)
+}
+
+// startPending starts the experiments that are due: every pending experiment in parallel mode,
+// or the next one once none is running in sequential mode. It reports whether an experiment
+// failed to start, in which case the outcome has to be aggregated again.
+func (r *RpcPlugin) startPending(chaosClient *chaos.Client, events *eventRecorder, analysisRun *v1alpha1.AnalysisRun, metric v1alpha1.Metric, config *Config, run *inflightRun, specs []ExperimentConfig, states []experimentState, measurement v1alpha1.Measurement) bool {
+	sequential := config.ExecutionMode == ExecutionModeSequential
+	if sequential {
+		for _, state := range states {
+			if state.Phase == v1alpha1.AnalysisPhaseRunning {
+				return false
+			}
+		}
+	}
+
+	deadline := measurementDeadline(measurement)
+	failed := false
+	for i := range states {
+		if states[i].Phase != v1alpha1.AnalysisPhasePending {
+			continue
+		}
+		if deadlinePassed(measurement, r.parseTimeout(config)) {
+			states[i].finish(v1alpha1.AnalysisPhaseError, "timeout before the experiment could start")
+			failed = true
+		} else if err := r.startExperiment(chaosClient, events, analysisRun, metric, config, run, specs[i], &states[i], deadline); err != nil {
+			failed = true
+		}
+		if sequential {
+			break
+		}
+	}
+	return failed
+}
+
+// startExperiment creates one experiment and starts watching it in the background
+func (r *RpcPlugin) startExperiment(chaosClient *chaos.Client, events *eventRecorder, analysisRun *v1alpha1.AnalysisRun, metric v1alpha1.Metric, config *Config, run *inflightRun, spec ExperimentConfig, state *experimentState, deadline time.Time) error {
+	value := config.TargetReplicaSetValue
+	if spec.role == roleBaseline {
+		value = config.BaselineReplicaSetValue
+	}
+	targetSelector := chaos.TargetSelector{
+		Labels:        map[string]string{config.TargetReplicaSetLabel: value},
+		MergeStrategy: config.SelectorMergeStrategy,
+		Namespace:     defaultNamespace(config.TargetNamespace, analysisRun),
+		NetworkSide:   config.NetworkTarget,
+	}
+	if config.PinTargetPods {
+		pods, err := r.pinTargetPods(run.ctx, chaosClient, spec.ChaosExperimentCRD, targetSelector)
+		if err != nil {
+			r.LogCtx.Errorf("Failed to pin target pods: %v", err)
+			events.record(run.ctx, chaos.EventTypeWarning, eventReasonFailed, "Failed to pin the target pods of metric %s: %v", metric.Name, err)
+			state.finish(v1alpha1.AnalysisPhaseError, err.Error())
+			return err
+		}
+		if len(pods) > 0 {
+			targetSelector.Pods = pods
+			state.PinnedPods = pinnedPodNames(pods)
+			events.record(run.ctx, chaos.EventTypeNormal, eventReasonPinned, "Pinned chaos experiment for metric %s to %d pods: %s", metric.Name, len(state.PinnedPods), strings.Join(state.PinnedPods, ", "))
+		}
+	}
+	r.LogCtx.Infof("Creating chaos experiment %s with target selector: %v", spec.Name, targetSelector.Labels)
+
+	tracking := trackingFor(analysisRun, metric, deadline)
+	tracking.Step = spec.Name
+	namespace := defaultNamespace(config.ExperimentNamespace, analysisRun)
+	experiment, overrides, err := chaosClient.CreateExperiment(run.ctx, spec.ChaosExperimentCRD, namespace, targetSelector, tracking)
+	if err != nil {
+		r.LogCtx.Errorf("Failed to create chaos experiment: %v", err)
+		events.record(run.ctx, chaos.EventTypeWarning, eventReasonFailed, "Failed to create chaos experiment for metric %s: %v", metric.Name, err)
+		state.finish(v1alpha1.AnalysisPhaseError, err.Error())
+		return err
+	}
+
+	r.LogCtx.Infof("Created chaos experiment: %s/%s (kind: %s)", experiment.GetNamespace(), experiment.GetName(), experiment.GetKind())
+	ref := chaos.RefFor(experiment)
+	events.record(run.ctx, chaos.EventTypeNormal, eventReasonCreated, "Created chaos experiment %s for metric %s", ref, metric.Name)
+	state.setExperiment(experiment)
+	state.Phase = v1alpha1.AnalysisPhaseRunning
+	state.SelectorWarning = selectorWarning(overrides)
+
+	// Schedules never finish on their own, their runs are listed on every reconcile instead
+	if ref.Kind != chaos.KindSchedule {
+		run.track(ref)
+		go r.watch(chaosClient, run, ref, deadline)
+	}
+	return nil
+}
+
+// observeExperiment updates the state of a running experiment, cleaning it up once it finished
+func (r *RpcPlugin) observeExperiment(ctx context.Context, chaosClient *chaos.Client, events *eventRecorder, analysisRun *v1alpha1.AnalysisRun, metric v1alpha1.Metric, config *Config, run *inflightRun, specs []ExperimentConfig, state *experimentState, measurement *v1alpha1.Measurement) {
+	// The nodes of a workflow are listed on every reconcile, as its abort only shows on them
+	known, hasRef := state.ref()
+	if hasRef && known.Kind == chaos.KindWorkflow {
+		r.observeWorkflowNodes(ctx, chaosClient, known, state)
+	}
+	aborted := chaos.WorkflowAborted(state.Nodes)
+
+	// Prefer the outcome of the background watch started in this process
+	var experiment *unstructured.Unstructured
+	if hasRef && run != nil {
+		watched, done, tracked, watchErr := run.result(known)
+		switch {
+		case tracked && !done && !aborted && !deadlinePassed(*measurement, r.parseTimeout(config)):
+			// The watch only wakes on changes of the experiment, but Chaos Mesh reports a
+			// failed selection through an Event alone
+			experiment = r.observeWatched(ctx, chaosClient, events, known, state)
+			if experiment == nil {
+				return
+			}
+		case done && watchErr == nil:
+			experiment = watched
+		}
+	}
+
+	if experiment == nil {
+		var err error
+		experiment, err = r.locateExperiment(ctx, chaosClient, analysisRun, metric, specs, *state)
+		if err != nil {
+			// Transient API errors are retried on the next reconcile until the deadline
+			if !errors.IsNotFound(err) && err != errNoExperiment && !deadlinePassed(*measurement, r.parseTimeout(config)) {
+				r.LogCtx.Warnf("Failed to get chaos experiment, will retry: %v", err)
+				return
+			}
+			r.LogCtx.Errorf("Failed to get chaos experiment: %v", err)
+			state.finish(v1alpha1.AnalysisPhaseError, err.Error())
+			return
+		}
+		state.setExperiment(experiment)
+
+		// Measurements that lost their deadline take it from the experiment object
+		if measurementDeadline(*measurement).IsZero() {
+			if deadline, ok := chaos.DeadlineOf(experiment); ok {
+				setDeadline(measurement, deadline)
+			}
+		}
+	}
+
+	ref := chaos.RefFor(experiment)
+	cleanup := func() { r.cleanup(ctx, chaosClient, events, config, ref) }
+
+	if ref.Kind == chaos.KindSchedule {
+		r.observeSchedule(ctx, chaosClient, events, config, run, experiment, state, *measurement)
+		return
+	}
+	if ref.Kind == chaos.KindWorkflow && !hasRef {
+		r.observeWorkflowNodes(ctx, chaosClient, ref, state)
+		aborted = chaos.WorkflowAborted(state.Nodes)
+	}
+
+	events.recordInjected(ctx, ref, state, chaos.ExperimentStats(experiment))
+	success, finished, err := chaosClient.ExperimentStatus(ctx, experiment)
+	if err == nil && !finished && aborted {
+		finished = true
+	}
+	if err == nil && !finished && !deadlinePassed(*measurement, r.parseTimeout(config)) {
+		return
+	}
+
+	stats := chaos.ExperimentStats(experiment)
+	state.Stats = &stats
+	state.Targets, state.TargetsOmitted = truncateTargets(chaos.ExperimentTargets(experiment))
+	events.recordTargets(ctx, ref, state)
+	switch {
+	case err != nil:
+		r.LogCtx.Errorf("Failed to get chaos experiment status: %v", err)
+		events.record(ctx, chaos.EventTypeWarning, eventReasonFailed, "Failed to read the status of chaos experiment %s: %v", ref, err)
+		state.finish(v1alpha1.AnalysisPhaseError, err.Error())
+	case !finished:
+		r.LogCtx.Errorf("Chaos experiment %s/%s did not complete in time", ref.Namespace, ref.Name)
+		events.record(ctx, chaos.EventTypeWarning, eventReasonTimedOut, "Chaos experiment %s did not complete in time", ref)
+		state.finish(v1alpha1.AnalysisPhaseError, joinMessages("timeout waiting for experiment to complete", r.controllerWarnings(ctx, chaosClient, ref)))
+	case success:
+		r.LogCtx.Infof("Chaos experiment %s/%s completed successfully", ref.Namespace, ref.Name)
+		events.record(ctx, chaos.EventTypeNormal, eventReasonSucceeded, "Chaos experiment %s completed successfully", ref)
+		state.finish(v1alpha1.AnalysisPhaseSuccessful, "")
+	default:
+		reason := chaos.FailureReason(experiment)
+		if aborted {
+			reason = joinMessages("workflow was aborted", reason)
+		}
+		message := joinMessages(reason, r.controllerWarnings(ctx, chaosClient, ref))
+		if message == "" {
+			message = "experiment failed"
+		}
+		r.LogCtx.Errorf("Chaos experiment %s/%s failed: %s", ref.Namespace, ref.Name, message)
+		events.record(ctx, chaos.EventTypeWarning, eventReasonFailed, "Chaos experiment %s failed: %s", ref, message)
+		state.finish(v1alpha1.AnalysisPhaseFailed, message)
+	}
+	r.cleanupExperiment(run, ref, cleanup)
+}
+
+// observeWatched reads an experiment still being watched on every reconcile. The ChaosInjected
+// Event is recorded as soon as its stats first show injected targets, and the experiment is
+// returned once its status reports it finished, e.g. because the chaos-controller-manager
+// recorded that it failed to select the targets, which the watch cannot see. Workflows only
+// finish through changes the watch sees, or through their nodes.
+func (r *RpcPlugin) observeWatched(ctx context.Context, chaosClient *chaos.Client, events *eventRecorder, ref chaos.ExperimentRef, state *experimentState) *unstructured.Unstructured {
+	if ref.Kind == chaos.KindWorkflow {
+		return nil
+	}
+	experiment, err := chaosClient.GetExperiment(ctx, ref)
+	if err != nil {
+		r.LogCtx.Debugf("Failed to get chaos experiment %s/%s, will retry: %v", ref.Namespace, ref.Name, err)
+		return nil
+	}
+	events.recordInjected(ctx, ref, state, chaos.ExperimentStats(experiment))
+	if _, finished, err := chaosClient.ExperimentStatus(ctx, experiment); err != nil || !finished {
+		return nil
+	}
+	return experiment
+}
+
+// observeWorkflowNodes records the node tree of a workflow, keeping the nodes seen last when
+// listing them fails
+func (r *RpcPlugin) observeWorkflowNodes(ctx context.Context, chaosClient *chaos.Client, ref chaos.ExperimentRef, state *experimentState) {
+	nodes, err := chaosClient.WorkflowNodes(ctx, ref)
+	if err != nil {
+		r.LogCtx.Warnf("Failed to list nodes of workflow %s/%s: %v", ref.Namespace, ref.Name, err)
+		return
+	}
+	state.Nodes = nodes
+}
+
+// defaultNamespace returns the configured namespace, falling back to the namespace of the
+// AnalysisRun so templates without one follow the Rollout across namespaces
+func defaultNamespace(configured string, analysisRun *v1alpha1.AnalysisRun) string {
+	if configured != "" {
+		return configured
+	}
+	return analysisRun.Namespace
+}
+
+// pinTargetPods lists the pods the target selector currently selects in the namespaces of the
+// pod selectors of the experiment, which the experiment is then pinned to. Experiments that
+// select no pods are not pinned. Finding no pods is an error, as the experiment would have
+// nothing to inject.
+func (r *RpcPlugin) pinTargetPods(ctx context.Context, chaosClient *chaos.Client, experimentYAML string, target chaos.TargetSelector) (map[string][]string, error) {
+	namespaces, err := chaosClient.TargetNamespaces(experimentYAML, target)
+	if err != nil {
+		return nil, err
+	}
+	if len(namespaces) == 0 {
+		r.LogCtx.Infof("Chaos experiment selects no pods, not pinning it")
+		return nil, nil
+	}
+
+	pods := make(map[string][]string, len(namespaces))
+	for _, namespace := range namespaces {
+		names, err := chaosClient.ListTargetPods(ctx, namespace, target.Labels)
+		if err != nil {
+			return nil, err
+		}
+		if len(names) > 0 {
+			pods[namespace] = names
+		}
+	}
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no pods match the target selector %v in namespaces %s", target.Labels, strings.Join(namespaces, ", "))
+	}
+	r.LogCtx.Infof("Pinning chaos experiment to pods %v", pods)
+	return pods, nil
+}
+
+// pinnedPodNames returns the pinned pods as namespace/name, sorted
+func pinnedPodNames(pods map[string][]string) []string {
+	var names []string
+	for namespace, podNames := range pods {
+		for _, name := range podNames {
+			names = append(names, namespace+"/"+name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectorWarning describes the template labels the target selector changed or dropped, or
+// returns an empty string when it overrode none
+func selectorWarning(overrides []chaos.SelectorOverride) string {
+	if len(overrides) == 0 {
+		return ""
+	}
+	descriptions := make([]string, len(overrides))
+	for i, override := range overrides {
+		descriptions[i] = override.String()
+	}
+	return "target selector overrides template labels: " + strings.Join(descriptions, "; ")
+}
+
+// controllerWarnings summarises the warnings the chaos-controller-manager recorded on the
+// experiment, which usually explain why it failed, e.g. that no pod was selected
+func (r *RpcPlugin) controllerWarnings(ctx context.Context, chaosClient *chaos.Client, ref chaos.ExperimentRef) string {
+	events, err := chaosClient.ExperimentEvents(ctx, ref)
+	if err != nil {
+		r.LogCtx.Warnf("Failed to list events of chaos experiment %s/%s: %v", ref.Namespace, ref.Name, err)
+		return ""
+	}
+	summary := chaos.SummarizeEvents(events)
+	if summary == "" {
+		return ""
+	}
+	return "controller reported: " + summary
+}
+
+// observeSchedule updates the state of a schedule from the runs it spawned. A schedule never
+// finishes on its own: its runs are judged once the measurement deadline closes the analysis
+// window, and the schedule is stopped then. The runs are merged into the history of earlier
+// reconciles, since Chaos Mesh prunes finished runs beyond the historyLimit of the schedule.
+func (r *RpcPlugin) observeSchedule(ctx context.Context, chaosClient *chaos.Client, events *eventRecorder, config *Config, run *inflightRun, schedule *unstructured.Unstructured, state *experimentState, measurement v1alpha1.Measurement) {
+	ref := chaos.RefFor(schedule)
+	listed, err := chaosClient.ListScheduleRuns(ctx, schedule)
+	var history chaos.ScheduleRunHistory
+	if state.RunHistory != nil {
+		history = *state.RunHistory
+	}
+	if err == nil {
+		history = history.Merge(listed)
+		state.RunHistory = &history
+	}
+	runs, stats := history.Summarize()
+	events.recordInjected(ctx, ref, state, stats)
+	switch {
+	case err != nil && !deadlinePassed(measurement, r.parseTimeout(config)):
+		r.LogCtx.Warnf("Failed to list runs of schedule %s/%s, will retry: %v", ref.Namespace, ref.Name, err)
+		return
+	case err != nil:
+		r.LogCtx.Errorf("Failed to list runs of schedule %s/%s: %v", ref.Namespace, ref.Name, err)
+		events.record(ctx, chaos.EventTypeWarning, eventReasonFailed, "Failed to list the runs of chaos schedule %s: %v", ref, err)
+		state.finish(v1alpha1.AnalysisPhaseError, err.Error())
+	default:
+		state.Runs = &runs
+		state.Stats = &stats
+		if !deadlinePassed(measurement, r.parseTimeout(config)) {
+			return
+		}
+		events.recordTargets(ctx, ref, state)
+		if runs.Success() {
+			r.LogCtx.Infof("Chaos schedule %s/%s completed: %s", ref.Namespace, ref.Name, runs)
+			events.record(ctx, chaos.EventTypeNormal, eventReasonSucceeded, "Chaos schedule %s completed: %s", ref, runs)
+			state.finish(v1alpha1.AnalysisPhaseSuccessful, runs.String())
+		} else {
+			r.LogCtx.Errorf("Chaos schedule %s/%s failed: %s", ref.Namespace, ref.Name, runs)
+			events.record(ctx, chaos.EventTypeWarning, eventReasonFailed, "Chaos schedule %s failed: %s", ref, runs)
+			state.finish(v1alpha1.AnalysisPhaseFailed, runs.String())
+		}
+	}
+
+	r.cleanupExperiment(run, ref, func() {
+		state.Action = r.stopExperiment(ctx, chaosClient, events, config, ref)
+	})
+}
+
+// completeMeasurement stops the experiments that are still running and finalizes the measurement
+func (r *RpcPlugin) completeMeasurement(ctx context.Context, chaosClient *chaos.Client, events *eventRecorder, metric v1alpha1.Metric, config *Config, run *inflightRun, states []experimentState, phase v1alpha1.AnalysisPhase, measurement v1alpha1.Measurement) v1alpha1.Measurement {
+	for i := range states {
+		ref, ok := states[i].ref()
+		if states[i].Phase != v1alpha1.AnalysisPhaseRunning || !ok {
+			continue
+		}
+		r.LogCtx.Infof("Stopping chaos experiment %s/%s, the measurement outcome is decided", ref.Namespace, ref.Name)
+		states[i].Phase = phaseStopped
+		r.cleanupExperiment(run, ref, func() {
+			states[i].Action = r.stopExperiment(ctx, chaosClient, events, config, ref)
+		})
+	}
+	if run != nil {
+		r.runs.finish(run)
+	}
+	saveExperimentStates(&measurement, states)
+
+	if phase != v1alpha1.AnalysisPhaseSuccessful && phase != v1alpha1.AnalysisPhaseFailed {
+		return metricutil.MarkMeasurementError(measurement, fmt.Errorf("%s", failureMessage(states)))
+	}
+
+	result := newMeasurementResult(states, phase == v1alpha1.AnalysisPhaseSuccessful)
+	if config.comparing() {
+		comparison := compare(states, config)
+		result.Comparison = &comparison
+	}
+	value, err := json.Marshal(result)
+	if err != nil {
+		return metricutil.MarkMeasurementError(measurement, err)
+	}
+	measurement.Value = string(value)
+
+	// Conditions refine the outcome of successful experiments; failed experiments always fail
+	if phase == v1alpha1.AnalysisPhaseSuccessful && hasConditions(metric) {
+		phase, err = r.evaluateResult(result, metric)
+		if err != nil {
+			r.LogCtx.Errorf("Failed to evaluate chaos experiment result: %v", err)
+			return metricutil.MarkMeasurementError(measurement, err)
+		}
+	}
+
+	switch phase {
+	case v1alpha1.AnalysisPhaseSuccessful:
+		r.LogCtx.Infof("Chaos experiment measurement completed successfully")
+		measurement.Message = joinMessages(result.comparisonMessage(), targetMessage(states))
+	case v1alpha1.AnalysisPhaseFailed:
+		r.LogCtx.Errorf("Chaos experiment measurement failed")
+		measurement.Message = joinMessages(result.comparisonMessage(), failureMessage(states), targetMessage(states))
+	default:
+		r.LogCtx.Warnf("Chaos experiment measurement is %s", phase)
+	}
+
+	finishedTime := timeutil.MetaNow()
+	measurement.FinishedAt = &finishedTime
+	measurement.ResumeAt = nil
+	measurement.Phase = phase
+	return measurement
+}
+
+// stopExperiment stops an experiment whose result is no longer needed: it is deleted when
+// cleanupOnFinish is set and paused otherwise, so the fault is always recovered
+func (r *RpcPlugin) stopExperiment(ctx context.Context, chaosClient *chaos.Client, events *eventRecorder, config *Config, ref chaos.ExperimentRef) string {
+	policy := TerminatePolicyPause
+	if config.CleanupOnFinish {
+		policy = TerminatePolicyDelete
+	}
+	action := r.applyTerminatePolicy(ctx, chaosClient, policy, ref)
+	events.recordStop(ctx, ref, action)
+	return action
+}
+
+// cleanupExperiment runs cleanup for the experiment exactly once. Without a registered
+// run, e.g. after a restart or once the measurement completed, cleanup runs directly unless
+// a finished run already cleaned the experiment up.
+func (r *RpcPlugin) cleanupExperiment(run *inflightRun, ref chaos.ExperimentRef, cleanup func()) {
+	if run != nil {
+		run.cleanupOnce(ref, cleanup)
+		return
+	}
+	if r.runs.cleanedRecently(ref) {
+		return
+	}
+	cleanup()
+}
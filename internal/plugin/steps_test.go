@@ -0,0 +1,692 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestAggregate(t *testing.T) {
+	states := func(phases ...v1alpha1.AnalysisPhase) []experimentState {
+		var result []experimentState
+		for _, phase := range phases {
+			result = append(result, experimentState{Phase: phase})
+		}
+		return result
+	}
+
+	tests := []struct {
+		name          string
+		aggregation   string
+		states        []experimentState
+		expectedPhase v1alpha1.AnalysisPhase
+		decided       bool
+	}{
+		{"All succeeded", AggregationAll, states(v1alpha1.AnalysisPhaseSuccessful, v1alpha1.AnalysisPhaseSuccessful), v1alpha1.AnalysisPhaseSuccessful, true},
+		{"All still running", AggregationAll, states(v1alpha1.AnalysisPhaseSuccessful, v1alpha1.AnalysisPhaseRunning), "", false},
+		{"All with one failure", AggregationAll, states(v1alpha1.AnalysisPhaseFailed, v1alpha1.AnalysisPhaseRunning), v1alpha1.AnalysisPhaseFailed, true},
+		{"All with one error", AggregationAll, states(v1alpha1.AnalysisPhaseError, v1alpha1.AnalysisPhasePending), v1alpha1.AnalysisPhaseError, true},
+		{"Any with one success", AggregationAny, states(v1alpha1.AnalysisPhaseRunning, v1alpha1.AnalysisPhaseSuccessful), v1alpha1.AnalysisPhaseSuccessful, true},
+		{"Any still running", AggregationAny, states(v1alpha1.AnalysisPhaseFailed, v1alpha1.AnalysisPhaseRunning), "", false},
+		{"Any all failed", AggregationAny, states(v1alpha1.AnalysisPhaseFailed, v1alpha1.AnalysisPhaseError), v1alpha1.AnalysisPhaseFailed, true},
+		{"Any all errored", AggregationAny, states(v1alpha1.AnalysisPhaseError, v1alpha1.AnalysisPhaseError), v1alpha1.AnalysisPhaseError, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			phase, decided := aggregate(test.states, test.aggregation)
+			if phase != test.expectedPhase || decided != test.decided {
+				t.Errorf("Expected (%s, %t), got (%s, %t)", test.expectedPhase, test.decided, phase, decided)
+			}
+		})
+	}
+}
+
+const testPodFailure = `apiVersion: chaos-mesh.org/v1alpha1
+kind: PodChaos
+metadata:
+  name: pod-failure
+  namespace: default
+spec:
+  action: pod-failure
+  mode: one
+  selector:
+    namespaces:
+      - default`
+
+// experimentStates decodes the per-experiment results recorded on the measurement
+func experimentStates(t *testing.T, measurement v1alpha1.Measurement) map[string]experimentState {
+	var states []experimentState
+	if err := json.Unmarshal([]byte(measurement.Metadata["experiments"]), &states); err != nil {
+		t.Fatalf("Failed to decode experiment states: %v", err)
+	}
+	result := make(map[string]experimentState)
+	for _, state := range states {
+		result[state.Name] = state
+	}
+	return result
+}
+
+// finishExperiment marks the experiment as finished the way Chaos Mesh does
+func finishExperiment(t *testing.T, dynamicClient *fake.FakeDynamicClient, name string) {
+	ctx := context.Background()
+	experiment, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get experiment %s: %v", name, err)
+	}
+	unstructured.SetNestedField(experiment.Object, "Finished", "status", "experiment", "phase")
+	if _, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Update(ctx, experiment, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update experiment %s: %v", name, err)
+	}
+}
+
+func TestRunSequentialExperiments(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	metric := newTestMetric(t, Config{
+		Experiments: []ExperimentConfig{
+			{Name: "kill", ChaosExperimentCRD: testPodChaos},
+			{Name: "failure", ChaosExperimentCRD: testPodFailure},
+		},
+		ExecutionMode:         ExecutionModeSequential,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+		CleanupOnFinish:       true,
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+
+	measurement := plugin.Run(analysisRun, metric)
+	if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+	}
+	states := experimentStates(t, measurement)
+	if states["kill"].Phase != v1alpha1.AnalysisPhaseRunning || states["failure"].Phase != v1alpha1.AnalysisPhasePending {
+		t.Fatalf("Expected only the first experiment to start, got %+v", states)
+	}
+
+	// The second experiment starts once the first one finished
+	waitForWatch(t, dynamicClient, 1)
+	finishExperiment(t, dynamicClient, states["kill"].Experiment)
+	for i := 0; i < 100 && experimentStates(t, measurement)["failure"].Phase == v1alpha1.AnalysisPhasePending; i++ {
+		measurement = plugin.Resume(analysisRun, metric, measurement)
+		time.Sleep(10 * time.Millisecond)
+	}
+	states = experimentStates(t, measurement)
+	if states["kill"].Phase != v1alpha1.AnalysisPhaseSuccessful || states["failure"].Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected the second experiment to start after the first succeeded, got %+v", states)
+	}
+
+	// Both experiments may share the informer of the first one, and Resume reads the experiment
+	// itself, so there is no second watch to wait for
+	finishExperiment(t, dynamicClient, states["failure"].Experiment)
+	measurement = resumeUntilCompleted(plugin, analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseSuccessful {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	}
+	if experimentStates(t, measurement)["failure"].Phase != v1alpha1.AnalysisPhaseSuccessful {
+		t.Errorf("Expected per-experiment result for 'failure' to be recorded, got %+v", experimentStates(t, measurement))
+	}
+}
+
+func TestRunParallelExperimentsAny(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	metric := newTestMetric(t, Config{
+		Experiments: []ExperimentConfig{
+			{Name: "kill", ChaosExperimentCRD: testPodChaos},
+			{Name: "failure", ChaosExperimentCRD: testPodFailure},
+		},
+		Aggregation:           AggregationAny,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+		CleanupOnFinish:       true,
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+
+	measurement := plugin.Run(analysisRun, metric)
+	states := experimentStates(t, measurement)
+	if states["kill"].Phase != v1alpha1.AnalysisPhaseRunning || states["failure"].Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected both experiments to start, got %+v", states)
+	}
+
+	// One success decides the measurement and stops the other experiment. Both experiments
+	// share one watch stream.
+	waitForWatch(t, dynamicClient, 1)
+	finishExperiment(t, dynamicClient, states["kill"].Experiment)
+	measurement = resumeUntilCompleted(plugin, analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseSuccessful {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	}
+
+	watches := 0
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() == "watch" {
+			watches++
+		}
+	}
+	if watches != 1 {
+		t.Errorf("Expected the experiments to share one watch, got %d watches", watches)
+	}
+
+	stopped := experimentStates(t, measurement)["failure"]
+	if stopped.Phase != phaseStopped || stopped.Action != "deleted" {
+		t.Errorf("Expected the remaining experiment to be stopped and deleted, got %+v", stopped)
+	}
+	_, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Get(context.Background(), stopped.Experiment, metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("Expected the remaining experiment to be deleted, got %v", err)
+	}
+}
+
+const testWorkflow = `apiVersion: chaos-mesh.org/v1alpha1
+kind: Workflow
+metadata:
+  name: canary-scenario
+  namespace: default
+spec:
+  entry: entry
+  templates:
+    - name: entry
+      templateType: Serial
+      children:
+        - kill-pod
+    - name: kill-pod
+      templateType: PodChaos
+      deadline: 30s
+      podChaos:
+        action: pod-kill
+        mode: one
+        selector:
+          namespaces:
+            - default`
+
+// createWorkflowNode creates a node of the workflow as Chaos Mesh does while running it,
+// with the given condition set to True unless it is empty. The labels are spelled out as
+// Chaos Mesh sets them (v1alpha1.LabelWorkflow and v1alpha1.LabelControlledBy), so a typo in
+// the constants of the plugin shows up here.
+func createWorkflowNode(t *testing.T, dynamicClient *fake.FakeDynamicClient, name, workflow, parent, template, condition string) {
+	labels := map[string]interface{}{"chaos-mesh.org/workflow": workflow}
+	if parent != "" {
+		labels["chaos-mesh.org/controlled-by"] = parent
+	}
+	node := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "chaos-mesh.org/v1alpha1",
+		"kind":       "WorkflowNode",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "default", "labels": labels},
+		"spec":       map[string]interface{}{"templateName": template, "type": "PodChaos"},
+	}}
+	if condition != "" {
+		unstructured.SetNestedSlice(node.Object, []interface{}{
+			map[string]interface{}{"type": condition, "status": "True"},
+		}, "status", "conditions")
+	}
+	if _, err := dynamicClient.Resource(workflowNodeGVR).Namespace("default").Create(context.Background(), node, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create workflow node: %v", err)
+	}
+}
+
+// measurementNodes decodes the workflow nodes published in the measurement metadata
+func measurementNodes(t *testing.T, measurement v1alpha1.Measurement) []chaos.WorkflowNodeStatus {
+	var nodes []chaos.WorkflowNodeStatus
+	if err := json.Unmarshal([]byte(measurement.Metadata[metadataWorkflowNodes]), &nodes); err != nil {
+		t.Fatalf("Failed to parse workflow nodes '%s': %v", measurement.Metadata[metadataWorkflowNodes], err)
+	}
+	return nodes
+}
+
+func TestRunWorkflow(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	metric := newTestMetric(t, Config{
+		ChaosExperimentCRD:    testWorkflow,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+		CleanupOnFinish:       true,
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+	ctx := context.Background()
+
+	measurement := plugin.Run(analysisRun, metric)
+	if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+	}
+	workflowName := measurement.Metadata["experimentName"]
+
+	workflow, err := dynamicClient.Resource(workflowGVR).Namespace("default").Get(ctx, workflowName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get workflow: %v", err)
+	}
+	templates, _, _ := unstructured.NestedSlice(workflow.Object, "spec", "templates")
+	injected, _, _ := unstructured.NestedString(templates[1].(map[string]interface{}), "podChaos", "selector", "labelSelectors", "rollouts-pod-template-hash")
+	if injected != "abc123" {
+		t.Errorf("Expected target selector to be injected into the workflow template, got '%s'", injected)
+	}
+
+	// Chaos Mesh creates a node per template, which shows up while the workflow is running
+	waitForWatch(t, dynamicClient, 1)
+	createWorkflowNode(t, dynamicClient, "kill-pod-abcde", workflowName, "", "kill-pod", "")
+	measurement = plugin.Resume(analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+	}
+	if nodes := measurementNodes(t, measurement); len(nodes) != 1 || nodes[0].Phase != chaos.NodePhaseRunning {
+		t.Errorf("Expected the running kill-pod node to be reported, got %+v", nodes)
+	}
+
+	// ...and marks the node and the workflow accomplished
+	node, err := dynamicClient.Resource(workflowNodeGVR).Namespace("default").Get(ctx, "kill-pod-abcde", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get workflow node: %v", err)
+	}
+	unstructured.SetNestedSlice(node.Object, []interface{}{
+		map[string]interface{}{"type": "Accomplished", "status": "True"},
+	}, "status", "conditions")
+	if _, err := dynamicClient.Resource(workflowNodeGVR).Namespace("default").Update(ctx, node, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update workflow node: %v", err)
+	}
+	unstructured.SetNestedSlice(workflow.Object, []interface{}{
+		map[string]interface{}{"type": "Accomplished", "status": "True"},
+	}, "status", "conditions")
+	if _, err := dynamicClient.Resource(workflowGVR).Namespace("default").Update(ctx, workflow, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update workflow: %v", err)
+	}
+
+	measurement = resumeUntilCompleted(plugin, analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseSuccessful {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	}
+
+	if nodes := measurementNodes(t, measurement); len(nodes) != 1 || nodes[0].Template != "kill-pod" || nodes[0].Phase != chaos.NodePhaseSucceeded {
+		t.Errorf("Expected the accomplished kill-pod node to be reported, got %+v", nodes)
+	}
+}
+
+func TestRunWorkflowAborted(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	metric := newTestMetric(t, Config{
+		ChaosExperimentCRD:    testWorkflow,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+
+	measurement := plugin.Run(analysisRun, metric)
+	if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+	}
+	workflowName := measurement.Metadata["experimentName"]
+	waitForWatch(t, dynamicClient, 1)
+
+	// An aborted child node does not abort the workflow...
+	createWorkflowNode(t, dynamicClient, "entry-abcde", workflowName, "", "entry", "")
+	createWorkflowNode(t, dynamicClient, "kill-pod-fghij", workflowName, "entry-abcde", "kill-pod", "Aborted")
+	measurement = plugin.Resume(analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+	}
+
+	// ...Chaos Mesh reports the abort on the entry node; the workflow itself never gets a condition for it
+	if err := dynamicClient.Resource(workflowNodeGVR).Namespace("default").Delete(context.Background(), "entry-abcde", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete workflow node: %v", err)
+	}
+	createWorkflowNode(t, dynamicClient, "entry-abcde", workflowName, "", "entry", "Aborted")
+
+	measurement = resumeUntilCompleted(plugin, analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseFailed {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseFailed, measurement.Phase, measurement.Message)
+	}
+	if !strings.Contains(measurement.Message, "workflow was aborted") {
+		t.Errorf("Expected the message to report the abort, got '%s'", measurement.Message)
+	}
+	if nodes := measurementNodes(t, measurement); len(nodes) != 2 || nodes[0].Phase != chaos.NodePhaseAborted {
+		t.Errorf("Expected the aborted entry node to be reported, got %+v", nodes)
+	}
+}
+
+const testSchedule = `apiVersion: chaos-mesh.org/v1alpha1
+kind: Schedule
+metadata:
+  name: recurring-kill
+  namespace: default
+spec:
+  schedule: "@every 30s"
+  type: PodChaos
+  historyLimit: 10
+  concurrencyPolicy: Forbid
+  podChaos:
+    action: pod-kill
+    mode: one
+    selector:
+      namespaces:
+        - default`
+
+// createScheduleRun creates a PodChaos spawned by the schedule, in the given legacy phase
+func createScheduleRun(t *testing.T, dynamicClient *fake.FakeDynamicClient, scheduleName, name, phase string) {
+	run := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "chaos-mesh.org/v1alpha1",
+		"kind":       "PodChaos",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "default",
+			"labels":    map[string]interface{}{chaos.LabelScheduledBy: scheduleName},
+		},
+		"status": map[string]interface{}{
+			"experiment": map[string]interface{}{"phase": phase},
+		},
+	}}
+	if _, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Create(context.Background(), run, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create schedule run: %v", err)
+	}
+}
+
+func TestRunSchedule(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	metric := newTestMetric(t, Config{
+		ChaosExperimentCRD:    testSchedule,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+	ctx := context.Background()
+
+	measurement := plugin.Run(analysisRun, metric)
+	if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+	}
+	scheduleName := measurement.Metadata["experimentName"]
+
+	// Chaos Mesh spawns a PodChaos on every tick of the schedule
+	createScheduleRun(t, dynamicClient, scheduleName, "run-1", "Finished")
+	createScheduleRun(t, dynamicClient, scheduleName, "run-2", "Finished")
+
+	// The schedule keeps running until the analysis window closes
+	measurement = plugin.Resume(analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+	}
+	if !strings.Contains(measurement.Metadata[metadataScheduleRuns], `"total":2`) {
+		t.Errorf("Expected the runs seen so far to be reported, got '%s'", measurement.Metadata[metadataScheduleRuns])
+	}
+
+	setDeadline(&measurement, time.Now().Add(-time.Second))
+	measurement = plugin.Resume(analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseSuccessful {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseSuccessful, measurement.Phase, measurement.Message)
+	}
+	var value measurementResult
+	if err := json.Unmarshal([]byte(measurement.Value), &value); err != nil || value.Runs == nil || value.Runs.Succeeded != 2 {
+		t.Errorf("Expected 2 successful runs in the result, got '%s' (%v)", measurement.Value, err)
+	}
+
+	_, err := dynamicClient.Resource(scheduleGVR).Namespace("default").Get(ctx, scheduleName, metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("Expected the schedule to be stopped and deleted at the end of the measurement, got %v", err)
+	}
+}
+
+func TestRunScheduleRemembersPrunedRuns(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	metric := newTestMetric(t, Config{
+		ChaosExperimentCRD:    testSchedule,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+
+	measurement := plugin.Run(analysisRun, metric)
+	scheduleName := measurement.Metadata["experimentName"]
+	createScheduleRun(t, dynamicClient, scheduleName, "run-1", "Failed")
+	createScheduleRun(t, dynamicClient, scheduleName, "run-2", "Finished")
+	measurement = plugin.Resume(analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+	}
+
+	// The historyLimit of the schedule prunes the failed run before the window closes
+	if err := dynamicClient.Resource(podChaosGVR).Namespace("default").Delete(context.Background(), "run-1", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete schedule run: %v", err)
+	}
+	createScheduleRun(t, dynamicClient, scheduleName, "run-3", "Finished")
+	measurement = plugin.Resume(analysisRun, metric, measurement)
+	if !strings.Contains(measurement.Metadata[metadataScheduleRuns], `"total":3`) {
+		t.Errorf("Expected the pruned run to still be counted, got '%s'", measurement.Metadata[metadataScheduleRuns])
+	}
+	var history chaos.ScheduleRunHistory
+	if err := json.Unmarshal([]byte(measurement.Metadata[metadataScheduleRunHistory]), &history); err != nil {
+		t.Fatalf("Failed to parse schedule run history: %v", err)
+	}
+	if len(history.Listed) != 2 || history.Pruned.Failed != 1 {
+		t.Errorf("Expected the pruned run to be folded into the totals, got %+v", history)
+	}
+
+	setDeadline(&measurement, time.Now().Add(-time.Second))
+	measurement = plugin.Resume(analysisRun, metric, measurement)
+	if measurement.Phase != v1alpha1.AnalysisPhaseFailed {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseFailed, measurement.Phase, measurement.Message)
+	}
+	if !strings.Contains(measurement.Message, "2 of 3 runs injected and recovered, 1 failed") {
+		t.Errorf("Expected the pruned failure in the message, got '%s'", measurement.Message)
+	}
+}
+
+const testLabeledPodChaos = `apiVersion: chaos-mesh.org/v1alpha1
+kind: PodChaos
+metadata:
+  name: pod-failure
+  namespace: default
+spec:
+  action: pod-failure
+  mode: one
+  selector:
+    labelSelectors:
+      app: payments
+      rollouts-pod-template-hash: stable`
+
+func TestRunReportsSelectorOverrides(t *testing.T) {
+	tests := []struct {
+		name            string
+		strategy        string
+		expectedWarning string
+		expectedErr     string
+	}{
+		{
+			name:            "merge",
+			strategy:        chaos.MergeStrategyMerge,
+			expectedWarning: "target selector overrides template labels: spec.selector: rollouts-pod-template-hash=stable overridden by rollouts-pod-template-hash=abc123",
+		},
+		{
+			name:        "failOnConflict",
+			strategy:    chaos.MergeStrategyFailOnConflict,
+			expectedErr: "conflicts with the target selector",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin, _ := newTestPlugin()
+			metric := newTestMetric(t, Config{
+				ChaosExperimentCRD:    testLabeledPodChaos,
+				TargetReplicaSetLabel: "rollouts-pod-template-hash",
+				TargetReplicaSetValue: "abc123",
+				SelectorMergeStrategy: tt.strategy,
+			})
+			analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+
+			measurement := plugin.Run(analysisRun, metric)
+			if tt.expectedErr != "" {
+				if measurement.Phase != v1alpha1.AnalysisPhaseError || !strings.Contains(measurement.Message, tt.expectedErr) {
+					t.Errorf("Expected an error containing '%s', got %s (%s)", tt.expectedErr, measurement.Phase, measurement.Message)
+				}
+				return
+			}
+			if measurement.Metadata["selectorWarning"] != tt.expectedWarning {
+				t.Errorf("Expected selector warning '%s', got '%s'", tt.expectedWarning, measurement.Metadata["selectorWarning"])
+			}
+		})
+	}
+}
+
+const testPodChaosWithoutNamespace = `apiVersion: chaos-mesh.org/v1alpha1
+kind: PodChaos
+metadata:
+  name: pod-failure
+spec:
+  action: pod-failure
+  mode: one`
+
+func TestRunDefaultsNamespaces(t *testing.T) {
+	tests := []struct {
+		name                string
+		experimentNamespace string
+		targetNamespace     string
+		expectedExperiment  string
+		expectedTarget      string
+	}{
+		{
+			name:               "From the AnalysisRun",
+			expectedExperiment: "team-a",
+			expectedTarget:     "team-a",
+		},
+		{
+			name:                "Overridden by the config",
+			experimentNamespace: "chaos-testing",
+			targetNamespace:     "team-b",
+			expectedExperiment:  "chaos-testing",
+			expectedTarget:      "team-b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin, dynamicClient := newTestPlugin()
+			metric := newTestMetric(t, Config{
+				ChaosExperimentCRD:    testPodChaosWithoutNamespace,
+				TargetReplicaSetLabel: "rollouts-pod-template-hash",
+				TargetReplicaSetValue: "abc123",
+				ExperimentNamespace:   tt.experimentNamespace,
+				TargetNamespace:       tt.targetNamespace,
+			})
+			analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "team-a", UID: "run-uid"}}
+
+			measurement := plugin.Run(analysisRun, metric)
+			if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+				t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+			}
+			if measurement.Metadata["experimentNamespace"] != tt.expectedExperiment {
+				t.Errorf("Expected the experiment in namespace %s, got %s", tt.expectedExperiment, measurement.Metadata["experimentNamespace"])
+			}
+
+			experiment, err := dynamicClient.Resource(podChaosGVR).Namespace(tt.expectedExperiment).Get(context.Background(), measurement.Metadata["experimentName"], metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Failed to get experiment: %v", err)
+			}
+			namespaces, _, _ := unstructured.NestedStringSlice(experiment.Object, "spec", "selector", "namespaces")
+			if len(namespaces) != 1 || namespaces[0] != tt.expectedTarget {
+				t.Errorf("Expected selector namespaces [%s], got %v", tt.expectedTarget, namespaces)
+			}
+			plugin.Terminate(analysisRun, metric, measurement)
+		})
+	}
+}
+
+// createPod creates a running pod of the ReplicaSet with the given pod template hash
+func createPod(t *testing.T, dynamicClient *fake.FakeDynamicClient, name, hash string) {
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "default",
+			"labels":    map[string]interface{}{"rollouts-pod-template-hash": hash},
+		},
+		"status": map[string]interface{}{"phase": "Running"},
+	}}
+	if _, err := dynamicClient.Resource(podGVR).Namespace("default").Create(context.Background(), pod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create pod: %v", err)
+	}
+}
+
+func TestRunPinsTargetPods(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	createPod(t, dynamicClient, "payments-abc123-b", "abc123")
+	createPod(t, dynamicClient, "payments-abc123-a", "abc123")
+	createPod(t, dynamicClient, "payments-stable-a", "stable")
+	metric := newTestMetric(t, Config{
+		ChaosExperimentCRD:    testPodChaos,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+		PinTargetPods:         true,
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+
+	measurement := plugin.Run(analysisRun, metric)
+	if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+	}
+	defer plugin.Terminate(analysisRun, metric, measurement)
+
+	expected := `["default/payments-abc123-a","default/payments-abc123-b"]`
+	if measurement.Metadata["pinnedPods"] != expected {
+		t.Errorf("Expected pinned pods %s, got '%s'", expected, measurement.Metadata["pinnedPods"])
+	}
+	experiment, err := dynamicClient.Resource(podChaosGVR).Namespace("default").Get(context.Background(), measurement.Metadata["experimentName"], metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get experiment: %v", err)
+	}
+	pods, _, _ := unstructured.NestedStringSlice(experiment.Object, "spec", "selector", "pods", "default")
+	if strings.Join(pods, ",") != "payments-abc123-a,payments-abc123-b" {
+		t.Errorf("Expected the experiment to be pinned to the canary pods, got %v", pods)
+	}
+}
+
+func TestRunPinTargetPodsWithoutPods(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	createPod(t, dynamicClient, "payments-stable-a", "stable")
+	metric := newTestMetric(t, Config{
+		ChaosExperimentCRD:    testPodChaos,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+		PinTargetPods:         true,
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+
+	measurement := plugin.Run(analysisRun, metric)
+	expected := "no pods match the target selector map[rollouts-pod-template-hash:abc123] in namespaces default"
+	if measurement.Phase != v1alpha1.AnalysisPhaseError || !strings.Contains(measurement.Message, expected) {
+		t.Errorf("Expected an error containing '%s', got %s (%s)", expected, measurement.Phase, measurement.Message)
+	}
+}
+
+func TestRunPinTargetPodsSkipsKindsWithoutPods(t *testing.T) {
+	plugin, _ := newTestPlugin()
+	metric := newTestMetric(t, Config{
+		ChaosExperimentCRD: `apiVersion: chaos-mesh.org/v1alpha1
+kind: AWSChaos
+metadata:
+  name: ec2-stop
+  namespace: default
+spec:
+  action: ec2-stop
+  awsRegion: us-east-1
+  ec2Instance: i-0123456789`,
+		TargetReplicaSetLabel: "rollouts-pod-template-hash",
+		TargetReplicaSetValue: "abc123",
+		PinTargetPods:         true,
+	})
+	analysisRun := &v1alpha1.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "analysis", Namespace: "default", UID: "run-uid"}}
+
+	measurement := plugin.Run(analysisRun, metric)
+	if measurement.Phase != v1alpha1.AnalysisPhaseRunning {
+		t.Fatalf("Expected phase %s, got %s (%s)", v1alpha1.AnalysisPhaseRunning, measurement.Phase, measurement.Message)
+	}
+	defer plugin.Terminate(analysisRun, metric, measurement)
+	if pinned, found := measurement.Metadata["pinnedPods"]; found {
+		t.Errorf("Expected no pinned pods for a kind that selects none, got %s", pinned)
+	}
+}
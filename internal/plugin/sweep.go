@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"context"
+	"time"
+
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
+)
+
+// DefaultOrphanSweepInterval is how often cross-namespace experiments whose AnalysisRun is
+// gone are looked for and deleted
+const DefaultOrphanSweepInterval = 5 * time.Minute
+
+// orphanSweep deletes orphaned cross-namespace experiments in the background until stopped
+type orphanSweep struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// startOrphanSweep deletes the orphaned cross-namespace experiments right away and then on
+// every OrphanSweepInterval. Kubernetes cannot garbage collect them through owner references,
+// and waiting for GarbageCollect would leave them, and possibly their faults, behind for good
+// once their AnalysisRun is deleted.
+func (r *RpcPlugin) startOrphanSweep(chaosClient *chaos.Client) {
+	r.stopOrphanSweep()
+
+	interval := r.OrphanSweepInterval
+	if interval <= 0 {
+		interval = DefaultOrphanSweepInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	sweep := &orphanSweep{cancel: cancel, done: make(chan struct{})}
+
+	r.sweepMu.Lock()
+	r.sweep = sweep
+	r.sweepMu.Unlock()
+
+	go func() {
+		defer close(sweep.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			r.deleteOrphanedExperiments(ctx, chaosClient)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stopOrphanSweep stops the background sweep, if one is running, and waits for it to return
+func (r *RpcPlugin) stopOrphanSweep() {
+	r.sweepMu.Lock()
+	sweep := r.sweep
+	r.sweep = nil
+	r.sweepMu.Unlock()
+
+	if sweep != nil {
+		sweep.cancel()
+		<-sweep.done
+	}
+}
+
+// deleteOrphanedExperiments runs one sweep, logging its outcome
+func (r *RpcPlugin) deleteOrphanedExperiments(ctx context.Context, chaosClient *chaos.Client) {
+	orphans, err := chaosClient.DeleteOrphanedExperiments(ctx)
+	switch {
+	case err != nil && ctx.Err() == nil:
+		r.LogCtx.Warnf("Failed to delete orphaned chaos experiments: %v", err)
+	case orphans > 0:
+		r.LogCtx.Infof("Deleted %d orphaned chaos experiment(s)", orphans)
+	}
+}
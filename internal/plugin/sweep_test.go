@@ -0,0 +1,61 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestOrphanSweep(t *testing.T) {
+	plugin, dynamicClient := newTestPlugin()
+	plugin.OrphanSweepInterval = 10 * time.Millisecond
+	chaosClient, err := plugin.chaosClient()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	// An experiment in another namespace than its AnalysisRun, which has been deleted
+	orphan := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "chaos-mesh.org/v1alpha1",
+			"kind":       "PodChaos",
+			"metadata": map[string]interface{}{
+				"name":        name,
+				"namespace":   "chaos-testing",
+				"labels":      map[string]interface{}{chaos.LabelManagedBy: chaos.ManagedByValue, chaos.LabelCrossNamespaceOwner: "true"},
+				"annotations": map[string]interface{}{chaos.AnnotationAnalysisRun: "team-a/deleted"},
+			},
+		}}
+	}
+	waitForDeletion := func(name string) {
+		for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(5 * time.Millisecond) {
+			_, err := dynamicClient.Resource(podChaosGVR).Namespace("chaos-testing").Get(ctx, name, metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				return
+			}
+		}
+		t.Fatalf("Expected the orphaned experiment %s to be deleted by the sweep", name)
+	}
+
+	// The first sweep runs right away, later ones on every interval
+	if _, err := dynamicClient.Resource(podChaosGVR).Namespace("chaos-testing").Create(ctx, orphan("left-before-start"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create experiment: %v", err)
+	}
+	plugin.startOrphanSweep(chaosClient)
+	waitForDeletion("left-before-start")
+	if _, err := dynamicClient.Resource(podChaosGVR).Namespace("chaos-testing").Create(ctx, orphan("left-later"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create experiment: %v", err)
+	}
+	waitForDeletion("left-later")
+
+	plugin.Shutdown()
+	if plugin.sweep != nil {
+		t.Errorf("Expected Shutdown to stop the sweep")
+	}
+}
@@ -1,6 +1,11 @@
 package main
 
 import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gabriellacanna/chaos-mesh-plugin/internal/chaos"
 	"github.com/gabriellacanna/chaos-mesh-plugin/internal/plugin"
 	rolloutsPlugin "github.com/argoproj/argo-rollouts/metricproviders/plugin/rpc"
 	goPlugin "github.com/hashicorp/go-plugin"
@@ -21,7 +26,9 @@ func main() {
 	logCtx := *log.WithFields(log.Fields{"plugin": "chaos-mesh"})
 
 	rpcPluginImp := &plugin.RpcPlugin{
-		LogCtx: logCtx,
+		LogCtx:              logCtx,
+		ClientOptions:       clientOptionsFromEnv(logCtx),
+		OrphanSweepInterval: orphanSweepIntervalFromEnv(logCtx),
 	}
 	
 	// pluginMap is the map of plugins we can dispense.
@@ -35,4 +42,49 @@ func main() {
 		HandshakeConfig: handshakeConfig,
 		Plugins:         pluginMap,
 	})
-}
\ No newline at end of file
+
+	rpcPluginImp.Shutdown()
+}
+
+// clientOptionsFromEnv reads the Kubernetes client tuning from the environment the
+// Argo Rollouts controller starts the plugin with
+func clientOptionsFromEnv(logCtx log.Entry) chaos.ClientOptions {
+	opts := chaos.ClientOptions{
+		UserAgent: os.Getenv("CHAOS_MESH_PLUGIN_USER_AGENT"),
+	}
+
+	if value := os.Getenv("CHAOS_MESH_PLUGIN_QPS"); value != "" {
+		qps, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			logCtx.Warnf("Ignoring invalid CHAOS_MESH_PLUGIN_QPS '%s': %v", value, err)
+		} else {
+			opts.QPS = float32(qps)
+		}
+	}
+
+	if value := os.Getenv("CHAOS_MESH_PLUGIN_BURST"); value != "" {
+		burst, err := strconv.Atoi(value)
+		if err != nil {
+			logCtx.Warnf("Ignoring invalid CHAOS_MESH_PLUGIN_BURST '%s': %v", value, err)
+		} else {
+			opts.Burst = burst
+		}
+	}
+
+	return opts
+}
+
+// orphanSweepIntervalFromEnv reads how often orphaned cross-namespace experiments are deleted,
+// or returns zero for the default
+func orphanSweepIntervalFromEnv(logCtx log.Entry) time.Duration {
+	value := os.Getenv("CHAOS_MESH_PLUGIN_ORPHAN_SWEEP_INTERVAL")
+	if value == "" {
+		return 0
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		logCtx.Warnf("Ignoring invalid CHAOS_MESH_PLUGIN_ORPHAN_SWEEP_INTERVAL '%s'", value)
+		return 0
+	}
+	return interval
+}